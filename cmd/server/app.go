@@ -1,6 +1,9 @@
 package main
 
 import (
+	"log"
+	"time"
+
 	_ "github.com/lib/pq"
 
 	"github.com/grantjforrester/go-ticket/internal/adapter/api"
@@ -9,6 +12,7 @@ import (
 	"github.com/grantjforrester/go-ticket/pkg/authz"
 	"github.com/grantjforrester/go-ticket/pkg/config"
 	"github.com/grantjforrester/go-ticket/pkg/media"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
 )
 
 type App interface {
@@ -18,16 +22,136 @@ type App interface {
 
 func NewApp(config config.Provider) App {
 	// secondary adapters
-	connectionPool := repository.NewSQLConnectionPool(config)
-	repository := repository.NewSQLTicketRepository(connectionPool)
+	repositories := newRepositories(config)
 
 	// services
-	authorizer := authz.AlwaysAuthorize{}
-	service := service.NewTicketService(repository, authorizer)
+	workflow := newWorkflow(config)
+	authorizer := newAuthorizer(config)
+	ticketService := service.NewTicketService(repositories.ticket, repositories.history, authorizer, workflow)
+	commentService := service.NewCommentService(repositories.comment, repositories.ticket, authorizer)
+	workflowService := service.NewWorkflowService(workflow, authorizer)
 
 	// primary adapters
 	mediaHandler := media.JSONHandler{ErrorMap: api.NewErrorMapper()}
-	api := api.NewAPI(config, api.Services{Ticket: service}, mediaHandler)
+	api := api.NewAPI(config, api.Services{
+		Ticket:   ticketService,
+		Comment:  commentService,
+		Workflow: workflowService,
+	}, mediaHandler)
+
+	if sweeper, ok := newTrashSweeper(config, ticketService); ok {
+		return apps{sweeper, api}
+	}
 
 	return api
 }
+
+// apps starts its apps in order and stops them in reverse order.
+type apps []App
+
+func (a apps) Start() {
+	for _, app := range a {
+		app.Start()
+	}
+}
+
+func (a apps) Stop() {
+	for i := len(a) - 1; i >= 0; i-- {
+		a[i].Stop()
+	}
+}
+
+// newTrashSweeper creates a sweeper that purges tickets in the trash for longer than the config
+// trash_retention, every config trash_sweep_interval (default 1h). Returns false if no retention given.
+func newTrashSweeper(config config.Provider, svc service.TicketService) (service.TrashSweeper, bool) {
+	retention := config.GetString("trash_retention")
+	if retention == "" {
+		return service.TrashSweeper{}, false
+	}
+
+	r, err := time.ParseDuration(retention)
+	if err != nil || r < 0 {
+		log.Panicln("invalid trash_retention:", retention)
+	}
+
+	interval := time.Hour
+	if s := config.GetString("trash_sweep_interval"); s != "" {
+		interval, err = time.ParseDuration(s)
+		if err != nil || interval <= 0 {
+			log.Panicln("invalid trash_sweep_interval:", s)
+		}
+	}
+
+	return service.NewTrashSweeper(svc, r, interval), true
+}
+
+// repositories holds repositories that share transactions.
+type repositories struct {
+	ticket  service.TicketRepository
+	comment service.CommentRepository
+	history service.HistoryRepository
+}
+
+// newRepositories creates in-memory repositories if config repository is "memory", otherwise
+// SQL repositories.
+func newRepositories(config config.Provider) repositories {
+	switch kind := config.GetString("repository"); kind {
+	case "memory":
+		store := repository.NewMemoryStore()
+		log.Println("Using in-memory repositories")
+		return repositories{
+			ticket:  repository.NewMemoryTicketRepository(store),
+			comment: repository.NewMemoryCommentRepository(store),
+			history: repository.NewMemoryHistoryRepository(),
+		}
+	case "", "sql":
+		connectionPool := repository.NewSQLConnectionPool(config)
+		migrateSchema(config, repository.NewSQLMigrator(connectionPool))
+		return repositories{
+			ticket:  repository.NewSQLTicketRepository(connectionPool),
+			comment: repository.NewSQLCommentRepository(connectionPool),
+			history: repository.NewSQLHistoryRepository(),
+		}
+	default:
+		log.Panicln("invalid repository:", kind)
+		return repositories{}
+	}
+}
+
+// newWorkflow loads the workflow file given by config, or returns the default workflow if none given.
+func newWorkflow(config config.Provider) workflow.Workflow {
+	filename := config.GetString("workflow_file")
+	if filename == "" {
+		return workflow.Default
+	}
+
+	w, err := workflow.Load(filename)
+	if err != nil {
+		log.Panicln(err)
+	}
+	log.Println("Workflow loaded from", filename)
+
+	return w
+}
+
+// newAuthorizer creates a role based authorizer from the policy file given by config, or an authorizer
+// that authorizes everything if none given. The conditions of the policy must be valid for the resources.
+func newAuthorizer(config config.Provider) authz.ResourceAuthorizer {
+	filename := config.GetString("authz_policy_file")
+	if filename == "" {
+		return authz.AlwaysAuthorize{}
+	}
+
+	p, err := authz.LoadPolicy(filename)
+	if err != nil {
+		log.Panicln(err)
+	}
+	log.Println("Authorization policy loaded from", filename)
+
+	authorizer, err := authz.NewRoleAuthorizer(p, service.AuthorizedResources()...)
+	if err != nil {
+		log.Panicln("invalid policy", filename+":", err)
+	}
+
+	return authorizer
+}
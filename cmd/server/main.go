@@ -12,6 +12,17 @@ import (
 func main() {
 	config := viper.New()
 	config.AutomaticEnv()
+
+	if len(os.Args) > 1 {
+		if os.Args[1] != "migrate" {
+			log.Fatalln(migrateUsage)
+		}
+		if err := runMigrate(config, os.Args[2:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	app := NewApp(config)
 	app.Start()
 
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	"github.com/grantjforrester/go-ticket/pkg/config"
+	"github.com/grantjforrester/go-ticket/pkg/migrate"
+)
+
+const migrateUsage = "usage: server migrate up | down | status"
+
+// migrateSchema applies pending migrations, or only verifies none are pending if config
+// db_migrate is "verify".
+func migrateSchema(config config.Provider, migrator migrate.Migrator) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	switch mode := config.GetString("db_migrate"); mode {
+	case "", "apply":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Panicln(err)
+		}
+		log.Println("Database migrations applied:", len(applied))
+	case "verify":
+		if err := migrator.Verify(ctx); err != nil {
+			log.Panicln(err)
+		}
+		log.Println("Database migrations verified")
+	default:
+		log.Panicln("invalid db_migrate:", mode)
+	}
+}
+
+// runMigrate runs the migrate command with the given arguments.
+func runMigrate(config config.Provider, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	connectionPool := repository.NewSQLConnectionPool(config)
+	defer connectionPool.Close()
+	migrator := repository.NewSQLMigrator(connectionPool)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range applied {
+			fmt.Printf("applied %d.%s\n", m.Version, m.Name)
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted != nil {
+			fmt.Printf("reverted %d.%s\n", reverted.Version, reverted.Name)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%d.%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
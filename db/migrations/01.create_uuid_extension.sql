@@ -1,3 +0,0 @@
-\connect tickets
-
-CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
\ No newline at end of file
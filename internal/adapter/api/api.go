@@ -11,6 +11,8 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/grantjforrester/go-ticket/pkg/authn"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
 	"github.com/grantjforrester/go-ticket/pkg/config"
 	"github.com/grantjforrester/go-ticket/pkg/media"
 
@@ -22,10 +24,14 @@ type API struct {
 	server       *http.Server
 	services     Services
 	mediaHandler media.Handler
+	verifier     *authn.Verifier
+	cursors      collection.CursorCodec
 }
 
 type Services struct {
-	Ticket service.TicketService
+	Ticket   service.TicketService
+	Comment  service.CommentService
+	Workflow service.WorkflowService
 }
 
 //go:embed openapi.yml
@@ -36,7 +42,7 @@ func NewAPI(config config.Provider, svcs Services, mh media.Handler) API {
 
 	rtr := mux.NewRouter()
 	srv := &http.Server{Addr: fmt.Sprintf(":%d", prt), Handler: rtr}
-	api := API{port: prt, server: srv, services: svcs, mediaHandler: mh}
+	api := API{port: prt, server: srv, services: svcs, mediaHandler: mh, verifier: newVerifier(config), cursors: newCursorCodec(config)}
 
 	// register standard endpoints
 	rtr.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
@@ -50,7 +56,13 @@ func NewAPI(config config.Provider, svcs Services, mh media.Handler) API {
 
 	// register api routes
 	v1 := rtr.PathPrefix("/api/v1").Subrouter()
+	if api.verifier != nil {
+		v1.Use(api.authenticate)
+	}
 	api.registerTicketRoutes(v1)
+	api.registerCommentRoutes(v1)
+	api.registerTrashRoutes(v1)
+	api.registerWorkflowRoutes(v1)
 
 	// default not found
 	rtr.NotFoundHandler = http.HandlerFunc(api.PathNotFound)
@@ -58,6 +70,11 @@ func NewAPI(config config.Provider, svcs Services, mh media.Handler) API {
 	return api
 }
 
+// Handler returns the handler serving all the endpoints of the API.
+func (api API) Handler() http.Handler {
+	return api.server.Handler
+}
+
 func (api API) PathNotFound(w http.ResponseWriter, r *http.Request) {
 	err := PathNotFoundError{Message: "resource not found: " + r.RequestURI}
 	api.mediaHandler.WriteError(w, &err)
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/api"
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/media"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
+)
+
+// newHandler returns the handler of an API storing resources in memory, serving requests as a
+// principal authorized for every operation.
+func newHandler(t *testing.T) http.Handler {
+	store := repository.NewMemoryStore()
+	authorizer, err := authz.NewRoleAuthorizer(authz.Policy{Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}}}, service.AuthorizedResources()...)
+	require.NoError(t, err)
+	tickets := repository.NewMemoryTicketRepository(store)
+
+	handler := api.NewAPI(mapConfig{"cursor_secret": "secret"}, api.Services{
+		Ticket:   service.NewTicketService(tickets, repository.NewMemoryHistoryRepository(), authorizer, workflow.Default),
+		Comment:  service.NewCommentService(repository.NewMemoryCommentRepository(store), tickets, authorizer),
+		Workflow: service.NewWorkflowService(workflow.Default, authorizer),
+	}, media.JSONHandler{ErrorMap: api.NewErrorMapper()}).Handler()
+
+	admin := authz.Principal{Subject: "admin", Roles: []string{"admin"}}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(authz.WithPrincipal(r.Context(), admin)))
+	})
+}
+
+// serve returns the response of the handler to a request with the body and headers, given as
+// name value pairs.
+func serve(handler http.Handler, method string, target string, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	return resp
+}
+
+// createTicket creates a ticket through the handler and returns it.
+func createTicket(t *testing.T, handler http.Handler) ticket.TicketWithMetadata {
+	resp := serve(handler, http.MethodPost, "/api/v1/tickets", `{"summary":"mock summary","status":"open"}`)
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+	return decode[ticket.TicketWithMetadata](t, resp)
+}
+
+func decode[T any](t *testing.T, resp *httptest.ResponseRecorder) T {
+	var v T
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &v))
+	return v
+}
+
+// mapConfig provides configuration from a map.
+type mapConfig map[string]any
+
+func (c mapConfig) Get(key string) any {
+	return c[key]
+}
+
+func (c mapConfig) GetString(key string) string {
+	s, _ := c[key].(string)
+	return s
+}
+
+func (c mapConfig) GetBool(key string) bool {
+	b, _ := c[key].(bool)
+	return b
+}
+
+func (c mapConfig) GetInt(key string) int {
+	i, _ := c[key].(int)
+	return i
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/grantjforrester/go-ticket/pkg/authn"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/config"
+)
+
+// newVerifier creates a bearer token verifier from the keys given by config.
+// Returns nil if no keys are given i.e. authentication is disabled.
+func newVerifier(config config.Provider) *authn.Verifier {
+	var (
+		keys authn.KeySet
+		err  error
+	)
+
+	switch {
+	case config.GetString("jwt_jwks_file") != "":
+		keys, err = authn.LoadJWKSFile(config.GetString("jwt_jwks_file"))
+	case config.GetString("jwt_key_file") != "":
+		keys, err = authn.LoadKeyFile(config.GetString("jwt_key_file"))
+	default:
+		log.Println("Authentication disabled")
+		return nil
+	}
+	if err != nil {
+		log.Panicln(err)
+	}
+	log.Println("Authentication enabled")
+
+	return &authn.Verifier{
+		Keys:        keys,
+		Issuer:      config.GetString("jwt_issuer"),
+		Audience:    config.GetString("jwt_audience"),
+		RolesClaim:  config.GetString("jwt_roles_claim"),
+		TenantClaim: config.GetString("jwt_tenant_claim"),
+	}
+}
+
+// authenticate is middleware that requires requests to carry a valid bearer token and places
+// the principal identified by the token on the request context.
+func (api *API) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		scheme, token, _ := strings.Cut(req.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			api.writeUnauthorized(resp, authn.AuthenticationError{Message: "missing bearer token"})
+			return
+		}
+
+		principal, err := api.verifier.Verify(strings.TrimSpace(token))
+		if err != nil {
+			api.writeUnauthorized(resp, err)
+			return
+		}
+
+		next.ServeHTTP(resp, req.WithContext(authz.WithPrincipal(req.Context(), principal)))
+	})
+}
+
+func (api *API) writeUnauthorized(resp http.ResponseWriter, err error) {
+	resp.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	api.mediaHandler.WriteError(resp, err)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+// Batch modes. Atomic batches apply all operations or none; independent batches apply each
+// operation regardless of the others.
+const (
+	BatchAtomic      = "atomic"
+	BatchIndependent = "independent"
+)
+
+// batchRequest is a list of ticket operations applied in order.
+type batchRequest struct {
+	Mode       string           `json:"mode"`
+	Operations []batchOperation `json:"operations"`
+}
+
+// batchOperation creates, updates or deletes a ticket. Updates and deletes name the ticket by id.
+// Updates give the ticket, including its version. Deletes may give the version to check.
+type batchOperation struct {
+	Op      ticket.Action             `json:"op"`
+	ID      string                    `json:"id,omitempty"`
+	Version string                    `json:"version,omitempty"`
+	Ticket  ticket.TicketWithMetadata `json:"ticket"`
+}
+
+// batchResponse lists the result of each operation of a batch, in order.
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+}
+
+// batchResult is the HTTP status of an operation, with the ticket created or updated by a
+// successful operation or the error of a failed operation.
+type batchResult struct {
+	Status int                        `json:"status"`
+	Ticket *ticket.TicketWithMetadata `json:"ticket,omitempty"`
+	Error  any                        `json:"error,omitempty"`
+}
+
+func (api *API) batchTickets(resp http.ResponseWriter, req *http.Request) {
+	batch := batchRequest{}
+	if err := api.mediaHandler.ReadResource(req, &batch); err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	atomic := true
+	switch batch.Mode {
+	case "", BatchAtomic:
+	case BatchIndependent:
+		atomic = false
+	default:
+		api.mediaHandler.WriteError(resp, service.RequestError{Message: "invalid mode: " + batch.Mode})
+		return
+	}
+
+	operations := make([]service.TicketOperation, len(batch.Operations))
+	for i, op := range batch.Operations {
+		operations[i] = service.TicketOperation{Action: op.Op, Ticket: op.Ticket}
+		if op.Op != ticket.ActionCreate {
+			operations[i].Ticket.ID = op.ID
+		}
+		if op.Op == ticket.ActionDelete {
+			operations[i].Ticket.Version = op.Version
+		}
+	}
+
+	results, err := api.services.Ticket.BatchTickets(req.Context(), operations, atomic)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	response := batchResponse{Results: make([]batchResult, len(results))}
+	for i, r := range results {
+		response.Results[i] = api.batchResult(operations[i], r)
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, response)
+}
+
+// batchResult returns the status and ticket or error of the result of an operation, as if the
+// operation had been requested alone.
+func (api *API) batchResult(op service.TicketOperation, result service.TicketOperationResult) batchResult {
+	if result.Err != nil {
+		status, errorResource := api.mediaHandler.FormatError(result.Err)
+		return batchResult{Status: status, Error: errorResource}
+	}
+
+	switch op.Action {
+	case ticket.ActionCreate:
+		return batchResult{Status: http.StatusCreated, Ticket: &result.Ticket}
+	case ticket.ActionUpdate:
+		return batchResult{Status: http.StatusOK, Ticket: &result.Ticket}
+	default:
+		return batchResult{Status: http.StatusNoContent}
+	}
+}
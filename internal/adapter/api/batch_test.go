@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchResponse is the response to a batch request.
+type batchResponse struct {
+	Results []struct {
+		Status int            `json:"status"`
+		Ticket map[string]any `json:"ticket"`
+		Error  map[string]any `json:"error"`
+	} `json:"results"`
+}
+
+func TestShouldReturnResultOfEachIndependentOperation(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	existing := createTicket(t, handler)
+	deleted := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPost, "/api/v1/tickets:batch", `{"mode":"independent","operations":[
+		{"op":"create","ticket":{"summary":"new ticket","status":"open"}},
+		{"op":"update","id":"`+existing.ID+`","ticket":{"summary":"new summary","status":"open","version":"`+existing.Version+`"}},
+		{"op":"delete","id":"`+deleted.ID+`","version":"`+deleted.Version+`"},
+		{"op":"delete","id":"missing"}
+	]}`)
+
+	// Then
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	batch := decode[batchResponse](t, resp)
+	require.Len(t, batch.Results, 4)
+	assert.Equal(t, http.StatusCreated, batch.Results[0].Status)
+	assert.Equal(t, "new ticket", batch.Results[0].Ticket["summary"])
+	assert.Equal(t, http.StatusOK, batch.Results[1].Status)
+	assert.Equal(t, "new summary", batch.Results[1].Ticket["summary"])
+	assert.Equal(t, http.StatusNoContent, batch.Results[2].Status)
+	assert.Nil(t, batch.Results[2].Ticket)
+	assert.Nil(t, batch.Results[2].Error)
+	assert.Equal(t, http.StatusNotFound, batch.Results[3].Status)
+	assert.Nil(t, batch.Results[3].Ticket)
+	assert.Equal(t, float64(http.StatusNotFound), batch.Results[3].Error["status"])
+}
+
+func TestShouldReturnFailedDependencyForAbortedAtomicOperations(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+
+	// When
+	resp := serve(handler, http.MethodPost, "/api/v1/tickets:batch", `{"operations":[
+		{"op":"create","ticket":{"summary":"new ticket","status":"open"}},
+		{"op":"create","ticket":{"summary":"closed ticket","status":"closed"}}
+	]}`)
+
+	// Then
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	batch := decode[batchResponse](t, resp)
+	require.Len(t, batch.Results, 2)
+	assert.Equal(t, http.StatusFailedDependency, batch.Results[0].Status)
+	assert.Equal(t, "ticket:err:faileddependency", batch.Results[0].Error["type"])
+	assert.Equal(t, http.StatusUnprocessableEntity, batch.Results[1].Status)
+	assert.Contains(t, serve(handler, http.MethodGet, "/api/v1/tickets", "").Body.String(), `"results":[]`)
+}
+
+func TestShouldRejectInvalidBatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+
+	// When
+	mode := serve(handler, http.MethodPost, "/api/v1/tickets:batch", `{"mode":"eventual","operations":[]}`)
+	operation := serve(handler, http.MethodPost, "/api/v1/tickets:batch", `{"operations":[{"op":"update","ticket":{}}]}`)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, mode.Code)
+	assert.Equal(t, http.StatusBadRequest, operation.Code)
+}
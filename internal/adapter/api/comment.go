@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+func (api *API) registerCommentRoutes(router *mux.Router) {
+	router.HandleFunc("/tickets/{key}/comments", api.queryComments).Methods("GET")
+	router.HandleFunc("/tickets/{key}/comments", api.createComment).Methods("POST")
+	router.HandleFunc("/tickets/{key}/comments/{commentKey}", api.readComment).Methods("GET")
+	router.HandleFunc("/tickets/{key}/comments/{commentKey}", api.updateComment).Methods("PUT")
+	router.HandleFunc("/tickets/{key}/comments/{commentKey}", api.deleteComment).Methods("DELETE")
+}
+
+func (api *API) queryComments(resp http.ResponseWriter, req *http.Request) {
+	ticketID := mux.Vars(req)["key"]
+	querySpec, err := api.parseQuery(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	comments, err := api.services.Comment.QueryComments(req.Context(), ticketID, querySpec)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	writePage(api, resp, req, querySpec, comments)
+}
+
+func (api *API) readComment(resp http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+
+	comment, err := api.services.Comment.ReadComment(req.Context(), vars["key"], vars["commentKey"])
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, comment)
+}
+
+func (api *API) createComment(resp http.ResponseWriter, req *http.Request) {
+	inComment := ticket.CommentWithMetadata{}
+	err := api.mediaHandler.ReadResource(req, &inComment)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+	inComment.TicketID = mux.Vars(req)["key"]
+
+	createdComment, err := api.services.Comment.CreateComment(req.Context(), inComment)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusCreated, createdComment)
+}
+
+func (api *API) updateComment(resp http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	inComment := ticket.CommentWithMetadata{}
+	err := api.mediaHandler.ReadResource(req, &inComment)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+	inComment.TicketID = vars["key"]
+	inComment.ID = vars["commentKey"]
+
+	updatedComment, err := api.services.Comment.UpdateComment(req.Context(), inComment)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, updatedComment)
+}
+
+func (api *API) deleteComment(resp http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	version, err := ifMatch(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	onlyIfExists, err := ifExists(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	err = api.services.Comment.DeleteComment(req.Context(), vars["key"], vars["commentKey"], version)
+	if err = ignoreNotFound(err, onlyIfExists); err != nil {
+		api.mediaHandler.WriteError(resp, preconditionFailed(err, version))
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusNoContent, nil)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+)
+
+// Entity tags of resources are their versions, so a client can make a request conditional on the
+// version of a resource it has read with the If-Match and If-None-Match headers.
+
+// ParamIfExists is the URL query parameter that makes a delete succeed if the resource does not exist.
+const ParamIfExists string = "ifExists"
+
+// etag returns the strong entity tag of a resource version.
+func etag(version string) string {
+	return `"` + version + `"`
+}
+
+// parseETags returns the entity tags listed in a conditional request header, or "*" for any.
+func parseETags(header string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// noneMatch returns true if the request has an If-None-Match header and the version matches one
+// of its entity tags. Tags are compared weakly, as RFC 9110 requires for If-None-Match.
+func noneMatch(req *http.Request, version string) bool {
+	for _, tag := range parseETags(req.Header.Get("If-None-Match")) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch returns the version required by the If-Match header of a request, or "" if the request
+// has no If-Match header or it is "*". Returns PreconditionFailedError if the header has a weak
+// entity tag, which never matches, or RequestError if it lists more than one entity tag.
+func ifMatch(req *http.Request) (string, error) {
+	tags := parseETags(req.Header.Get("If-Match"))
+	switch {
+	case len(tags) == 0 || (len(tags) == 1 && tags[0] == "*"):
+		return "", nil
+	case len(tags) > 1:
+		return "", service.RequestError{Message: "invalid If-Match: expected * or a single entity tag"}
+	case strings.HasPrefix(tags[0], "W/"):
+		return "", PreconditionFailedError{Message: "If-Match does not match weak entity tag " + tags[0]}
+	case len(tags[0]) < 2 || !strings.HasPrefix(tags[0], `"`) || !strings.HasSuffix(tags[0], `"`):
+		return "", service.RequestError{Message: "invalid If-Match: " + tags[0] + " is not an entity tag"}
+	default:
+		return tags[0][1 : len(tags[0])-1], nil
+	}
+}
+
+// preconditionFailed returns PreconditionFailedError in place of the ConflictError returned when
+// the resource version does not match the version required by the If-Match header of a request.
+func preconditionFailed(err error, version string) error {
+	if version != "" && errors.As(err, &repository.ConflictError{}) {
+		return PreconditionFailedError{Message: "If-Match " + etag(version) + " does not match current version"}
+	}
+	return err
+}
+
+// ifExists returns true if the request only deletes a resource if it exists, so deleting a resource
+// that does not exist succeeds. Returns RequestError if the ifExists parameter is not true or false.
+func ifExists(req *http.Request) (bool, error) {
+	value := req.URL.Query().Get(ParamIfExists)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, service.RequestError{Message: "invalid " + ParamIfExists + ": " + value}
+	}
+
+	return b, nil
+}
+
+// ignoreNotFound returns nil in place of the error returned when a resource does not exist, if the
+// request only deletes the resource if it exists.
+func ignoreNotFound(err error, ifExists bool) error {
+	if ifExists && (errors.As(err, &repository.NotFoundError{}) || errors.As(err, &service.NotFoundError{})) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldReturnETagOfTicketVersion(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "")
+
+	// Then
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, `"`+created.Version+`"`, resp.Header().Get("ETag"))
+}
+
+func TestShouldReturnNotModifiedIfNoneMatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	strong := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "", "If-None-Match", `"`+created.Version+`"`)
+	weak := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "", "If-None-Match", `"other", W/"`+created.Version+`"`)
+	wildcard := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "", "If-None-Match", "*")
+	other := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "", "If-None-Match", `"other"`)
+
+	// Then
+	assert.Equal(t, http.StatusNotModified, strong.Code)
+	assert.Empty(t, strong.Body.String())
+	assert.Equal(t, `"`+created.Version+`"`, strong.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, weak.Code)
+	assert.Equal(t, http.StatusNotModified, wildcard.Code)
+	assert.Equal(t, http.StatusOK, other.Code)
+}
+
+func TestShouldUpdateIfMatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPut, "/api/v1/tickets/"+created.ID, `{"summary":"new summary","status":"open"}`,
+		"If-Match", `"`+created.Version+`"`)
+
+	// Then
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.NotEqual(t, `"`+created.Version+`"`, resp.Header().Get("ETag"))
+}
+
+func TestShouldReturnPreconditionFailedIfNotMatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	update := serve(handler, http.MethodPut, "/api/v1/tickets/"+created.ID, `{"summary":"new summary","status":"open"}`,
+		"If-Match", `"other"`)
+	remove := serve(handler, http.MethodDelete, "/api/v1/tickets/"+created.ID, "", "If-Match", `"other"`)
+
+	// Then
+	assert.Equal(t, http.StatusPreconditionFailed, update.Code)
+	assert.Equal(t, http.StatusPreconditionFailed, remove.Code)
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "").Code)
+}
+
+func TestShouldRejectWeakIfMatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPut, "/api/v1/tickets/"+created.ID, `{"summary":"new summary","status":"open"}`,
+		"If-Match", `W/"`+created.Version+`"`)
+
+	// Then
+	assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+}
+
+func TestShouldRejectListIfMatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPut, "/api/v1/tickets/"+created.ID, `{"summary":"new summary","status":"open"}`,
+		"If-Match", `"other", "`+created.Version+`"`)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestShouldRejectIfMatchNotEntityTag(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPut, "/api/v1/tickets/"+created.ID, `{"summary":"new summary","status":"open"}`,
+		"If-Match", created.Version)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -7,3 +7,13 @@ type PathNotFoundError struct {
 func (ve *PathNotFoundError) Error() string {
 	return ve.Message
 }
+
+// PreconditionFailedError is returned when the resource does not match the conditions of a
+// conditional request.
+type PreconditionFailedError struct {
+	Message string
+}
+
+func (pe PreconditionFailedError) Error() string {
+	return pe.Message
+}
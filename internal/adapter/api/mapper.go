@@ -1,11 +1,14 @@
 package api
 
 import (
-	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
 	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/authn"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
 	"github.com/grantjforrester/go-ticket/pkg/collection"
 	"github.com/grantjforrester/go-ticket/pkg/media"
 	"github.com/grantjforrester/go-ticket/pkg/media/errors"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
 )
 
 func NewErrorMapper() errors.ErrorMapper {
@@ -24,11 +27,41 @@ func NewErrorMapper() errors.ErrorMapper {
 		Status:  400,
 		Title:   "Bad Request",
 	})
+	errorMapper.RegisterError((*service.NotFoundError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:notfound",
+		Status:  404,
+		Title:   "Not Found",
+	})
+	errorMapper.RegisterError((*service.UnprocessableError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:unprocessable",
+		Status:  422,
+		Title:   "Unprocessable Entity",
+	})
+	errorMapper.RegisterError((*service.BatchAbortedError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:faileddependency",
+		Status:  424,
+		Title:   "Failed Dependency",
+	})
 	errorMapper.RegisterError((*media.MediaError)(nil), errors.RFC7807Error{
 		TypeURI: "ticket:err:badrequest",
 		Status:  400,
 		Title:   "Bad Request",
 	})
+	errorMapper.RegisterError((*media.UnsupportedMediaTypeError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:unsupportedmediatype",
+		Status:  415,
+		Title:   "Unsupported Media Type",
+	})
+	errorMapper.RegisterError((*media.PatchError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:unprocessable",
+		Status:  422,
+		Title:   "Unprocessable Entity",
+	})
+	errorMapper.RegisterError((*media.PatchConflictError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:conflict",
+		Status:  409,
+		Title:   "Conflict",
+	})
 	errorMapper.RegisterError((*collection.QueryError)(nil), errors.RFC7807Error{
 		TypeURI: "ticket:err:badrequest",
 		Status:  400,
@@ -44,6 +77,26 @@ func NewErrorMapper() errors.ErrorMapper {
 		Status:  409,
 		Title:   "Conflict",
 	})
+	errorMapper.RegisterError((*PreconditionFailedError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:preconditionfailed",
+		Status:  412,
+		Title:   "Precondition Failed",
+	})
+	errorMapper.RegisterError((*authn.AuthenticationError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:unauthorized",
+		Status:  401,
+		Title:   "Unauthorized",
+	})
+	errorMapper.RegisterError((*authz.AuthorizationError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:forbidden",
+		Status:  403,
+		Title:   "Forbidden",
+	})
+	errorMapper.RegisterError((*workflow.TransitionError)(nil), errors.RFC7807Error{
+		TypeURI: "ticket:err:invalidtransition",
+		Status:  422,
+		Title:   "Invalid Transition",
+	})
 
 	return &errorMapper
 }
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/rand"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/collection/rsql"
+	"github.com/grantjforrester/go-ticket/pkg/config"
+	"github.com/grantjforrester/go-ticket/pkg/media"
+)
+
+// newCursorCodec creates a codec for the query cursors returned to clients, signed with the
+// secret given by config. Without a secret a random one is used, so cursors are only valid
+// until the server restarts and only on this server.
+func newCursorCodec(config config.Provider) collection.CursorCodec {
+	if secret := config.GetString("cursor_secret"); secret != "" {
+		return collection.CursorCodec{Key: []byte(secret)}
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Panicln(err)
+	}
+	log.Println("Cursor secret not set, using random secret")
+
+	return collection.CursorCodec{Key: key}
+}
+
+// parseQuery returns the collection query in the request URL, which continues from the
+// position of a previous page if it has a cursor. Filters may be given in CQL, RSQL or both.
+func (api *API) parseQuery(req *http.Request) (collection.QuerySpec, error) {
+	urlQuery, _ := url.ParseQuery(req.URL.RawQuery)
+	querySpec, err := cql.ParseQuery(urlQuery)
+	if err != nil {
+		return collection.QuerySpec{}, err
+	}
+
+	filters, err := rsql.ParseFilters(urlQuery[rsql.ParamQuery])
+	if err != nil {
+		return collection.QuerySpec{}, err
+	}
+	querySpec.Filters = append(querySpec.Filters, filters...)
+
+	if c := urlQuery.Get(cql.ParamCursor); c != "" {
+		if querySpec.Page != 0 {
+			return collection.QuerySpec{}, collection.QueryError{Message: "invalid query: page and cursor are exclusive"}
+		}
+		cursor, err := api.cursors.Decode(c)
+		if err != nil {
+			return collection.QuerySpec{}, err
+		}
+		querySpec.After = &cursor
+	}
+
+	return querySpec, nil
+}
+
+// parseFacets returns the facet query in the request URL. Filters may be given in CQL, RSQL or both.
+func parseFacets(req *http.Request) (collection.FacetSpec, error) {
+	urlQuery, _ := url.ParseQuery(req.URL.RawQuery)
+	facetSpec, err := cql.ParseFacets(urlQuery)
+	if err != nil {
+		return collection.FacetSpec{}, err
+	}
+
+	filters, err := rsql.ParseFilters(urlQuery[rsql.ParamQuery])
+	if err != nil {
+		return collection.FacetSpec{}, err
+	}
+	facetSpec.Filters = append(facetSpec.Filters, filters...)
+
+	return facetSpec, nil
+}
+
+// writePage writes the page returned for the query to the response, with the next cursor of the
+// page and a Link header for each related page. Links are built from the request URL, with the
+// page and size the service used for the query. If the query selects fields, only those fields
+// and the id, version and search match of each result are written.
+func writePage[T any](api *API, resp http.ResponseWriter, req *http.Request, query collection.QuerySpec, page collection.Page[T]) {
+	if page.Next != nil {
+		nextCursor, err := api.cursors.Encode(*page.Next)
+		if err != nil {
+			api.mediaHandler.WriteError(resp, err)
+			return
+		}
+		page.NextCursor = nextCursor
+	}
+
+	service.ApplyQueryDefaults(&query)
+	for _, link := range cql.PageLinks(*req.URL, query, page) {
+		resp.Header().Add("Link", link.String())
+	}
+
+	if query.Fields != nil {
+		fields := append([]string{"id", "version", "match"}, query.Fields...)
+		api.mediaHandler.WriteResponse(resp, http.StatusOK, media.SparseResource{Resource: page, Fields: fields, Items: "results"})
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, page)
+}
@@ -2,26 +2,34 @@ package api
 
 import (
 	"net/http"
-	"net/url"
 	"path"
 
 	"github.com/gorilla/mux"
 
-	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
 	"github.com/grantjforrester/go-ticket/pkg/ticket"
 )
 
+// ticketResult is a ticket found by a query, with how it matched the search of the query.
+type ticketResult struct {
+	ticket.TicketWithMetadata
+	Match *collection.Match `json:"match,omitempty"`
+}
+
 func (api *API) registerTicketRoutes(router *mux.Router) {
 	router.HandleFunc("/tickets", api.queryTickets).Methods("GET")
 	router.HandleFunc("/tickets", api.createTicket).Methods("POST")
+	router.HandleFunc("/tickets/facets", api.queryTicketFacets).Methods("GET")
+	router.HandleFunc("/tickets:batch", api.batchTickets).Methods("POST")
 	router.HandleFunc("/tickets/{key}", api.readTicket).Methods("GET")
 	router.HandleFunc("/tickets/{key}", api.updateTicket).Methods("PUT")
+	router.HandleFunc("/tickets/{key}", api.patchTicket).Methods("PATCH")
 	router.HandleFunc("/tickets/{key}", api.deleteTicket).Methods("DELETE")
+	router.HandleFunc("/tickets/{key}/history", api.queryTicketHistory).Methods("GET")
 }
 
 func (api *API) queryTickets(resp http.ResponseWriter, req *http.Request) {
-	urlQuery, _ := url.ParseQuery(req.URL.RawQuery)
-	querySpec, err := cql.ParseQuery(urlQuery)
+	querySpec, err := api.parseQuery(req)
 	if err != nil {
 		api.mediaHandler.WriteError(resp, err)
 		return
@@ -33,7 +41,23 @@ func (api *API) queryTickets(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	api.mediaHandler.WriteResponse(resp, http.StatusOK, tickets)
+	writePage(api, resp, req, querySpec, ticketResults(tickets))
+}
+
+func (api *API) queryTicketFacets(resp http.ResponseWriter, req *http.Request) {
+	facetSpec, err := parseFacets(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	facets, err := api.services.Ticket.QueryTicketFacets(req.Context(), facetSpec)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, facets)
 }
 
 func (api *API) readTicket(resp http.ResponseWriter, req *http.Request) {
@@ -45,6 +69,12 @@ func (api *API) readTicket(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	resp.Header().Set("ETag", etag(ticket.Version))
+	if noneMatch(req, ticket.Version) {
+		resp.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	api.mediaHandler.WriteResponse(resp, http.StatusOK, ticket)
 }
 
@@ -62,6 +92,7 @@ func (api *API) createTicket(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	resp.Header().Set("ETag", etag(createdTicket.Version))
 	api.mediaHandler.WriteResponse(resp, http.StatusCreated, createdTicket)
 }
 
@@ -75,23 +106,113 @@ func (api *API) updateTicket(resp http.ResponseWriter, req *http.Request) {
 	}
 	inTicket.ID = ticketID
 
-	updatedTicket, err := api.services.Ticket.UpdateTicket(req.Context(), inTicket)
+	version, err := ifMatch(req)
 	if err != nil {
 		api.mediaHandler.WriteError(resp, err)
 		return
 	}
+	if version != "" {
+		inTicket.Version = version
+	}
+
+	updatedTicket, err := api.services.Ticket.UpdateTicket(req.Context(), inTicket)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, preconditionFailed(err, version))
+		return
+	}
 
+	resp.Header().Set("ETag", etag(updatedTicket.Version))
 	api.mediaHandler.WriteResponse(resp, http.StatusOK, updatedTicket)
 }
 
+func (api *API) patchTicket(resp http.ResponseWriter, req *http.Request) {
+	ticketID := path.Base(req.URL.Path)
+	patch, err := api.mediaHandler.ReadPatch(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	version, err := ifMatch(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	patchedTicket, err := api.services.Ticket.PatchTicket(req.Context(), ticketID, version,
+		func(t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
+			patched := ticket.TicketWithMetadata{}
+			err := patch.Apply(t, &patched)
+			return patched, err
+		})
+	if err != nil {
+		api.mediaHandler.WriteError(resp, preconditionFailed(err, version))
+		return
+	}
+
+	resp.Header().Set("ETag", etag(patchedTicket.Version))
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, patchedTicket)
+}
+
 func (api *API) deleteTicket(resp http.ResponseWriter, req *http.Request) {
 	ticketID := path.Base(req.URL.Path)
 
-	err := api.services.Ticket.DeleteTicket(req.Context(), ticketID)
+	version, err := ifMatch(req)
 	if err != nil {
 		api.mediaHandler.WriteError(resp, err)
 		return
 	}
 
+	onlyIfExists, err := ifExists(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	err = api.services.Ticket.DeleteTicket(req.Context(), ticketID, version)
+	if err = ignoreNotFound(err, onlyIfExists); err != nil {
+		api.mediaHandler.WriteError(resp, preconditionFailed(err, version))
+		return
+	}
+
 	api.mediaHandler.WriteResponse(resp, http.StatusNoContent, nil)
 }
+
+func (api *API) queryTicketHistory(resp http.ResponseWriter, req *http.Request) {
+	ticketID := mux.Vars(req)["key"]
+	querySpec, err := api.parseQuery(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	history, err := api.services.Ticket.QueryTicketHistory(req.Context(), ticketID, querySpec)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	writePage(api, resp, req, querySpec, history)
+}
+
+// ticketResults returns the page of tickets as results with how each matched the search of the
+// query.
+func ticketResults(page collection.Page[ticket.TicketWithMetadata]) collection.Page[ticketResult] {
+	results := make([]ticketResult, len(page.Results))
+	for i, t := range page.Results {
+		results[i] = ticketResult{TicketWithMetadata: t}
+		if page.Matches != nil {
+			results[i].Match = page.Matches[i]
+		}
+	}
+
+	return collection.Page[ticketResult]{
+		Results:    results,
+		Page:       page.Page,
+		Size:       page.Size,
+		Total:      page.Total,
+		Pages:      page.Pages,
+		Next:       page.Next,
+		NextCursor: page.NextCursor,
+	}
+}
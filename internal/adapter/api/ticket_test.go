@@ -0,0 +1,147 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grantjforrester/go-ticket/pkg/media"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+func TestShouldMergePatchTicket(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID, `{"summary":"merged summary"}`,
+		"Content-Type", media.MergePatchType)
+
+	// Then
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	patched := decode[ticket.TicketWithMetadata](t, resp)
+	assert.Equal(t, "merged summary", patched.Summary)
+	assert.Equal(t, created.Status, patched.Status)
+	assert.Equal(t, `"`+patched.Version+`"`, resp.Header().Get("ETag"))
+}
+
+func TestShouldJSONPatchTicket(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID,
+		`[{"op":"test","path":"/status","value":"open"},{"op":"replace","path":"/status","value":"in_progress"}]`,
+		"Content-Type", media.JSONPatchType)
+
+	// Then
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	patched := decode[ticket.TicketWithMetadata](t, resp)
+	assert.Equal(t, "in_progress", patched.Status)
+	assert.Equal(t, created.Summary, patched.Summary)
+}
+
+func TestShouldRejectUnsupportedPatchType(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID, `{"summary":"merged summary"}`)
+
+	// Then
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+}
+
+func TestShouldReturnConflictOnFailedPatchTest(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID,
+		`[{"op":"test","path":"/status","value":"closed"},{"op":"replace","path":"/summary","value":"new summary"}]`,
+		"Content-Type", media.JSONPatchType)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	read := decode[ticket.TicketWithMetadata](t, serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, ""))
+	assert.Equal(t, created.Summary, read.Summary)
+}
+
+func TestShouldReturnUnprocessableOnInvalidPatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	wrongType := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID, `[{"op":"replace","path":"/summary","value":1}]`,
+		"Content-Type", media.JSONPatchType)
+	missingPath := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID, `[{"op":"remove","path":"/unknown"}]`,
+		"Content-Type", media.JSONPatchType)
+	invalidTicket := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID, `{"summary":null}`,
+		"Content-Type", media.MergePatchType)
+
+	// Then
+	assert.Equal(t, http.StatusUnprocessableEntity, wrongType.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, missingPath.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, invalidTicket.Code)
+}
+
+func TestShouldRejectMalformedPatch(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID, `[{"op":"replace"`,
+		"Content-Type", media.JSONPatchType)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestShouldReturnMatchOfSearchedTicketsOnly(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	createTicket(t, handler)
+
+	// When
+	searched := serve(handler, http.MethodGet, "/api/v1/tickets?search=mock", "")
+	queried := serve(handler, http.MethodGet, "/api/v1/tickets", "")
+
+	// Then
+	assert.Equal(t, http.StatusOK, searched.Code, searched.Body.String())
+	searchedPage := decode[ticketPage](t, searched)
+	assert.Len(t, searchedPage.Results, 1)
+	assert.Equal(t, "<b>mock</b> summary", searchedPage.Results[0]["match"].(map[string]any)["snippet"])
+	assert.Equal(t, http.StatusOK, queried.Code, queried.Body.String())
+	queriedPage := decode[ticketPage](t, queried)
+	assert.Len(t, queriedPage.Results, 1)
+	assert.NotContains(t, queriedPage.Results[0], "match")
+}
+
+func TestShouldNotStoreMatchOfPatchedTicket(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	resp := serve(handler, http.MethodPatch, "/api/v1/tickets/"+created.ID,
+		`[{"op":"add","path":"/match","value":{"rank":1,"snippet":"<script>"}}]`,
+		"Content-Type", media.JSONPatchType)
+	read := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "")
+
+	// Then
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.NotContains(t, decode[map[string]any](t, resp), "match")
+	assert.NotContains(t, decode[map[string]any](t, read), "match")
+}
+
+// ticketPage is a page of tickets returned by a query.
+type ticketPage struct {
+	Results []map[string]any `json:"results"`
+}
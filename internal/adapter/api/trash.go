@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+)
+
+// ParamBefore is the query parameter giving the time before which tickets moved to the trash are purged.
+const ParamBefore = "before"
+
+// purgeResult lists the ids of the tickets purged from the trash.
+type purgeResult struct {
+	Purged []string `json:"purged"`
+}
+
+func (api *API) registerTrashRoutes(router *mux.Router) {
+	router.HandleFunc("/trash/tickets", api.queryTrashedTickets).Methods("GET")
+	router.HandleFunc("/trash/tickets", api.purgeTrash).Methods("DELETE")
+	router.HandleFunc("/trash/tickets/{key}", api.purgeTicket).Methods("DELETE")
+	router.HandleFunc("/trash/tickets/{key}/restore", api.restoreTicket).Methods("POST")
+}
+
+func (api *API) queryTrashedTickets(resp http.ResponseWriter, req *http.Request) {
+	querySpec, err := api.parseQuery(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	tickets, err := api.services.Ticket.QueryTrashedTickets(req.Context(), querySpec)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	writePage(api, resp, req, querySpec, ticketResults(tickets))
+}
+
+func (api *API) restoreTicket(resp http.ResponseWriter, req *http.Request) {
+	ticketID := mux.Vars(req)["key"]
+
+	ticket, err := api.services.Ticket.RestoreTicket(req.Context(), ticketID)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	resp.Header().Set("ETag", etag(ticket.Version))
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, ticket)
+}
+
+func (api *API) purgeTicket(resp http.ResponseWriter, req *http.Request) {
+	ticketID := mux.Vars(req)["key"]
+
+	onlyIfExists, err := ifExists(req)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	err = api.services.Ticket.PurgeTicket(req.Context(), ticketID)
+	if err = ignoreNotFound(err, onlyIfExists); err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusNoContent, nil)
+}
+
+// purgeTrash purges the tickets moved to the trash before the time given by ParamBefore, or all
+// tickets in the trash if not given.
+func (api *API) purgeTrash(resp http.ResponseWriter, req *http.Request) {
+	before := time.Now()
+	if value := req.URL.Query().Get(ParamBefore); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			api.mediaHandler.WriteError(resp, service.RequestError{Message: "invalid before: " + value + " is not a valid RFC 3339 time"})
+			return
+		}
+		before = t
+	}
+
+	purged, err := api.services.Ticket.PurgeTrash(req.Context(), before)
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, purgeResult{Purged: purged})
+}
@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+func TestShouldRestoreDeletedTicket(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+	require.Equal(t, http.StatusNoContent, serve(handler, http.MethodDelete, "/api/v1/tickets/"+created.ID, "").Code)
+
+	// When
+	deleted := serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "")
+	restore := serve(handler, http.MethodPost, "/api/v1/trash/tickets/"+created.ID+"/restore", "")
+	restoreAgain := serve(handler, http.MethodPost, "/api/v1/trash/tickets/"+created.ID+"/restore", "")
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, deleted.Code)
+	assert.Equal(t, http.StatusOK, restore.Code, restore.Body.String())
+	restored := decode[ticket.TicketWithMetadata](t, restore)
+	assert.Equal(t, `"`+restored.Version+`"`, restore.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotFound, restoreAgain.Code)
+	assert.Equal(t, http.StatusOK, serve(handler, http.MethodGet, "/api/v1/tickets/"+created.ID, "").Code)
+}
+
+func TestShouldPurgeTrashBefore(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+	require.Equal(t, http.StatusNoContent, serve(handler, http.MethodDelete, "/api/v1/tickets/"+created.ID, "").Code)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	// When
+	beforePast := serve(handler, http.MethodDelete, "/api/v1/trash/tickets?before="+past, "")
+	all := serve(handler, http.MethodDelete, "/api/v1/trash/tickets", "")
+	invalid := serve(handler, http.MethodDelete, "/api/v1/trash/tickets?before=yesterday", "")
+
+	// Then
+	assert.Equal(t, http.StatusOK, beforePast.Code)
+	assert.JSONEq(t, `{"purged":[]}`, beforePast.Body.String())
+	assert.Equal(t, http.StatusOK, all.Code)
+	assert.JSONEq(t, `{"purged":["`+created.ID+`"]}`, all.Body.String())
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
+
+func TestShouldPurgeMissingTicketIfExists(t *testing.T) {
+	// Given
+	handler := newHandler(t)
+	created := createTicket(t, handler)
+
+	// When
+	live := serve(handler, http.MethodDelete, "/api/v1/trash/tickets/"+created.ID, "")
+	missing := serve(handler, http.MethodDelete, "/api/v1/trash/tickets/missing", "")
+	missingIfExists := serve(handler, http.MethodDelete, "/api/v1/trash/tickets/missing?ifExists=true", "")
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, live.Code)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, http.StatusNoContent, missingIfExists.Code)
+}
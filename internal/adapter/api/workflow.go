@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (api *API) registerWorkflowRoutes(router *mux.Router) {
+	router.HandleFunc("/workflow", api.readWorkflow).Methods("GET")
+}
+
+func (api *API) readWorkflow(resp http.ResponseWriter, req *http.Request) {
+	workflow, err := api.services.Workflow.ReadWorkflow(req.Context())
+	if err != nil {
+		api.mediaHandler.WriteError(resp, err)
+		return
+	}
+
+	api.mediaHandler.WriteResponse(resp, http.StatusOK, workflow)
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+type SQLCommentRepository struct {
+	connectionPool *sql.DB
+}
+
+var _ repository.Repository[ticket.CommentWithMetadata] = (*SQLCommentRepository)(nil)
+
+var commentFields = []string{"id", "version", "ticket_id", "author", "body", "created_at", "updated_at", "created_by", "updated_by"}
+
+func NewSQLCommentRepository(pool *sql.DB) SQLCommentRepository {
+	return SQLCommentRepository{connectionPool: pool}
+}
+
+func (s SQLCommentRepository) Create(tx repository.Tx, c ticket.CommentWithMetadata) (ticket.CommentWithMetadata, error) {
+	ptx := tx.(*sql.Tx)
+	var uuid string
+
+	err := ptx.QueryRow(`INSERT INTO comments (id, version, ticket_id, author, body, created_by, updated_by)
+							VALUES (uuid_generate_v4(), 0, $1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+							RETURNING id`, c.TicketID, c.Author, c.Body, c.CreatedBy, c.UpdatedBy).Scan(&uuid)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("insert statement failed: %w", err)
+	}
+
+	createdComment, err := s.Read(tx, uuid)
+
+	return createdComment, err
+}
+
+func (s SQLCommentRepository) Read(tx repository.Tx, commentID string) (ticket.CommentWithMetadata, error) {
+	ptx := tx.(*sql.Tx)
+	row := ptx.QueryRow(`SELECT `+strings.Join(commentFields, ", ")+`
+							FROM comments
+							WHERE id = $1`, commentID)
+
+	c := ticket.CommentWithMetadata{}
+	switch err := row.Scan(scanComment(&c)...); err {
+	case nil:
+		return c, nil
+	case sql.ErrNoRows:
+		return ticket.CommentWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no comment with id %s found", commentID)}
+	default:
+		return ticket.CommentWithMetadata{}, err
+	}
+}
+
+func (s SQLCommentRepository) Update(tx repository.Tx, c ticket.CommentWithMetadata) (ticket.CommentWithMetadata, error) {
+	ptx := tx.(*sql.Tx)
+	_, err := s.Read(tx, c.Metadata.ID)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("read comment failed: %w", err)
+	}
+
+	res, err := ptx.Exec(`UPDATE comments
+							SET body = $3, updated_at = now(), updated_by = NULLIF($4, '')
+							WHERE id = $1
+							AND version = $2`,
+		c.ID, c.Version, c.Body, c.UpdatedBy)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("update statement failed: %w", err)
+	}
+
+	rowCount, err := res.RowsAffected()
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("count of updated rows failed: %w", err)
+	}
+	if rowCount != 1 {
+		return ticket.CommentWithMetadata{}, repository.ConflictError{Message: "version conflict"}
+	}
+
+	return s.Read(tx, c.Metadata.ID)
+}
+
+func (s SQLCommentRepository) Delete(tx repository.Tx, commentID string, version string) error {
+	ptx := tx.(*sql.Tx)
+	_, err := s.Read(tx, commentID)
+	if err != nil {
+		return fmt.Errorf("read comment failed: %w", err)
+	}
+
+	var res sql.Result
+	if version == "" {
+		res, err = ptx.Exec(`DELETE FROM comments WHERE id = $1`, commentID)
+	} else {
+		res, err = ptx.Exec(`DELETE FROM comments
+							WHERE id = $1
+							AND version = $2`, commentID, version)
+	}
+	if err != nil {
+		return fmt.Errorf("delete statement failed: %w", err)
+	}
+
+	rowCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("count of deleted rows failed: %w", err)
+	}
+	if rowCount != 1 {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	return nil
+}
+
+func (s SQLCommentRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.CommentWithMetadata], error) {
+	ptx := tx.(*sql.Tx)
+	qspec := query.(collection.QuerySpec)
+	results := []ticket.CommentWithMetadata{}
+	sqlQuery := cql.SQLQuery{
+		Fields:   commentFields,
+		Table:    "comments",
+		Query:    qspec,
+		KeyField: "id",
+	}
+	qry, args, err := sqlQuery.ToSQL()
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, fmt.Errorf("building sql query failed): %w", err)
+	}
+
+	rows, err := ptx.Query(qry, args...)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{},
+			fmt.Errorf("executing query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := ticket.CommentWithMetadata{}
+		err := rows.Scan(scanComment(&c)...)
+		if err != nil {
+			return collection.Page[ticket.CommentWithMetadata]{},
+				fmt.Errorf("error reading row: %w", err)
+		}
+		results = append(results, c)
+	}
+
+	size := uint64(len(results))
+	page := uint64(0)
+	if size > 0 {
+		page = qspec.Page
+	}
+	next, err := cql.NextCursor(qspec, "id", results)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, err
+	}
+
+	p := collection.Page[ticket.CommentWithMetadata]{
+		Results: results,
+		Page:    page,
+		Size:    size,
+		Next:    next,
+	}
+
+	if qspec.Count {
+		total, err := countSQL(ptx, sqlQuery)
+		if err != nil {
+			return collection.Page[ticket.CommentWithMetadata]{}, err
+		}
+		p = p.WithTotal(total, qspec.Size)
+	}
+
+	return p, nil
+}
+
+func (s SQLCommentRepository) StartTx(ctx context.Context, readOnly bool) (repository.Tx, error) {
+	opts := sql.TxOptions{Isolation: sql.LevelDefault, ReadOnly: readOnly}
+	tx, err := s.connectionPool.BeginTx(ctx, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("start tx failed: %w", err)
+	}
+	return tx, nil
+}
+
+// scanComment returns the destinations in the comment of the values of commentFields.
+func scanComment(c *ticket.CommentWithMetadata) []any {
+	return []any{&c.ID, &c.Version, &c.TicketID, &c.Author, &c.Body, &c.CreatedAt, &c.UpdatedAt,
+		(*nullString)(&c.CreatedBy), (*nullString)(&c.UpdatedBy)}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+// SQLHistoryRepository is an append-only store of ticket history entries. It has no transactions
+// of its own and is always used within a transaction of the SQLTicketRepository.
+type SQLHistoryRepository struct {
+}
+
+func NewSQLHistoryRepository() SQLHistoryRepository {
+	return SQLHistoryRepository{}
+}
+
+func (s SQLHistoryRepository) Append(tx repository.Tx, h ticket.HistoryEntry) error {
+	ptx := tx.(*sql.Tx)
+
+	changes, err := json.Marshal(h.Changes)
+	if err != nil {
+		return fmt.Errorf("encoding changes failed: %w", err)
+	}
+
+	_, err = ptx.Exec(`INSERT INTO ticket_history (id, ticket_id, action, changed_by, changes)
+							VALUES (uuid_generate_v4(), $1, $2, $3, $4)`,
+		h.TicketID, h.Action, h.ChangedBy, string(changes))
+	if err != nil {
+		return fmt.Errorf("insert statement failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s SQLHistoryRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.HistoryEntry], error) {
+	ptx := tx.(*sql.Tx)
+	qspec := query.(collection.QuerySpec)
+	results := []ticket.HistoryEntry{}
+	sqlQuery := cql.SQLQuery{
+		Fields:   []string{"id", "ticket_id", "action", "changed_by", "changed_at", "changes"},
+		Table:    "ticket_history",
+		Query:    qspec,
+		KeyField: "id",
+	}
+	qry, args, err := sqlQuery.ToSQL()
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, fmt.Errorf("building sql query failed): %w", err)
+	}
+
+	rows, err := ptx.Query(qry, args...)
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{},
+			fmt.Errorf("executing query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		h := ticket.HistoryEntry{}
+		var changes []byte
+		err := rows.Scan(&h.ID, &h.TicketID, &h.Action, &h.ChangedBy, &h.ChangedAt, &changes)
+		if err != nil {
+			return collection.Page[ticket.HistoryEntry]{},
+				fmt.Errorf("error reading row: %w", err)
+		}
+		if err := json.Unmarshal(changes, &h.Changes); err != nil {
+			return collection.Page[ticket.HistoryEntry]{},
+				fmt.Errorf("decoding changes failed: %w", err)
+		}
+		results = append(results, h)
+	}
+
+	size := uint64(len(results))
+	page := uint64(0)
+	if size > 0 {
+		page = qspec.Page
+	}
+	next, err := cql.NextCursor(qspec, "id", results)
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, err
+	}
+
+	p := collection.Page[ticket.HistoryEntry]{
+		Results: results,
+		Page:    page,
+		Size:    size,
+		Next:    next,
+	}
+
+	if qspec.Count {
+		total, err := countSQL(ptx, sqlQuery)
+		if err != nil {
+			return collection.Page[ticket.HistoryEntry]{}, err
+		}
+		p = p.WithTotal(total, qspec.Size)
+	}
+
+	return p, nil
+}
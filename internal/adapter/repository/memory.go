@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+)
+
+// MemoryStore holds the tables of in-memory repositories. Repositories created with the same store
+// share transactions.
+//
+// Each transaction works on a snapshot of the store taken when it starts. Only one read-write
+// transaction runs at a time and its changes replace the contents of the store when committed.
+type MemoryStore struct {
+	mutex  sync.Mutex
+	writer sync.Mutex
+	tables map[string]memoryTable
+}
+
+// MemoryTx is a transaction of a MemoryStore.
+type MemoryTx struct {
+	store    *MemoryStore
+	readOnly bool
+	tables   map[string]memoryTable
+	done     bool
+}
+
+var _ repository.Tx = (*MemoryTx)(nil)
+
+// memoryTable holds rows by id and remembers the order rows were inserted.
+type memoryTable struct {
+	rows map[string]any
+	ids  []string
+}
+
+var errTxDone = errors.New("transaction has already been committed or rolled back")
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tables: map[string]memoryTable{}}
+}
+
+// StartTx starts a new transaction on a snapshot of the store. Starting a read-write transaction
+// waits until any running read-write transaction has finished. A read-write transaction holds the
+// store's writer lock until it is committed or rolled back, so callers must always finish it, and
+// should finish it promptly, as every other writer of the store waits meanwhile.
+func (s *MemoryStore) StartTx(_ context.Context, readOnly bool) (repository.Tx, error) {
+	if !readOnly {
+		s.writer.Lock()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tables := make(map[string]memoryTable, len(s.tables))
+	for name, table := range s.tables {
+		tables[name] = table.clone()
+	}
+
+	return &MemoryTx{store: s, readOnly: readOnly, tables: tables}, nil
+}
+
+// Commit replaces the contents of the store with the transaction's snapshot.
+func (tx *MemoryTx) Commit() error {
+	if tx.done {
+		return errTxDone
+	}
+	tx.done = true
+
+	if !tx.readOnly {
+		tx.store.mutex.Lock()
+		tx.store.tables = tx.tables
+		tx.store.mutex.Unlock()
+		tx.store.writer.Unlock()
+	}
+
+	return nil
+}
+
+// Rollback discards the transaction's snapshot.
+func (tx *MemoryTx) Rollback() error {
+	if tx.done {
+		return errTxDone
+	}
+	tx.done = true
+
+	if !tx.readOnly {
+		tx.store.writer.Unlock()
+	}
+
+	return nil
+}
+
+// get returns the row with the id in the table.
+func (tx *MemoryTx) get(table string, id string) (any, bool) {
+	row, ok := tx.tables[table].rows[id]
+	return row, ok
+}
+
+// put inserts or replaces the row with the id in the table.
+func (tx *MemoryTx) put(table string, id string, row any) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	t, ok := tx.tables[table]
+	if !ok {
+		t = memoryTable{rows: map[string]any{}}
+	}
+	if _, ok := t.rows[id]; !ok {
+		t.ids = append(t.ids, id)
+	}
+	t.rows[id] = row
+	tx.tables[table] = t
+
+	return nil
+}
+
+// remove deletes the row with the id from the table, if present.
+func (tx *MemoryTx) remove(table string, id string) error {
+	if err := tx.checkWritable(); err != nil {
+		return err
+	}
+
+	t, ok := tx.tables[table]
+	if _, found := t.rows[id]; !ok || !found {
+		return nil
+	}
+	delete(t.rows, id)
+	for i, rowID := range t.ids {
+		if rowID == id {
+			t.ids = append(t.ids[:i:i], t.ids[i+1:]...)
+			break
+		}
+	}
+	tx.tables[table] = t
+
+	return nil
+}
+
+func (tx *MemoryTx) checkWritable() error {
+	if tx.done {
+		return errTxDone
+	}
+	if tx.readOnly {
+		return errors.New("cannot write in a read-only transaction")
+	}
+	return nil
+}
+
+// memoryRows returns all rows of the table in insertion order.
+func memoryRows[T any](tx *MemoryTx, table string) []T {
+	t := tx.tables[table]
+	rows := make([]T, len(t.ids))
+	for i, id := range t.ids {
+		rows[i] = t.rows[id].(T)
+	}
+	return rows
+}
+
+func (t memoryTable) clone() memoryTable {
+	rows := make(map[string]any, len(t.rows))
+	for id, row := range t.rows {
+		rows[id] = row
+	}
+	return memoryTable{rows: rows, ids: append([]string{}, t.ids...)}
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
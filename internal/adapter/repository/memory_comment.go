@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+type MemoryCommentRepository struct {
+	store *MemoryStore
+}
+
+var _ repository.Repository[ticket.CommentWithMetadata] = (*MemoryCommentRepository)(nil)
+
+func NewMemoryCommentRepository(store *MemoryStore) MemoryCommentRepository {
+	return MemoryCommentRepository{store: store}
+}
+
+func (m MemoryCommentRepository) Create(tx repository.Tx, c ticket.CommentWithMetadata) (ticket.CommentWithMetadata, error) {
+	mtx := tx.(*MemoryTx)
+	c.ID = newUUID()
+	c.Version = "0"
+	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+
+	if err := mtx.put(commentsTable, c.ID, c); err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("insert failed: %w", err)
+	}
+
+	return c, nil
+}
+
+func (m MemoryCommentRepository) Read(tx repository.Tx, commentID string) (ticket.CommentWithMetadata, error) {
+	mtx := tx.(*MemoryTx)
+
+	row, ok := mtx.get(commentsTable, commentID)
+	if !ok {
+		return ticket.CommentWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no comment with id %s found", commentID)}
+	}
+
+	return row.(ticket.CommentWithMetadata), nil
+}
+
+// Update changes the body of the comment. All other properties are preserved.
+func (m MemoryCommentRepository) Update(tx repository.Tx, c ticket.CommentWithMetadata) (ticket.CommentWithMetadata, error) {
+	mtx := tx.(*MemoryTx)
+	current, err := m.Read(tx, c.Metadata.ID)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("read comment failed: %w", err)
+	}
+
+	if current.Version != c.Version {
+		return ticket.CommentWithMetadata{}, repository.ConflictError{Message: "version conflict"}
+	}
+
+	updated := current
+	updated.Body = c.Body
+	updated.UpdatedAt = time.Now()
+	updated.UpdatedBy = c.UpdatedBy
+	updated.Version, err = nextVersion(current.Version)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	if err := mtx.put(commentsTable, updated.ID, updated); err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("update failed: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (m MemoryCommentRepository) Delete(tx repository.Tx, commentID string, version string) error {
+	mtx := tx.(*MemoryTx)
+	current, err := m.Read(tx, commentID)
+	if err != nil {
+		return fmt.Errorf("read comment failed: %w", err)
+	}
+
+	if version != "" && current.Version != version {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	if err := mtx.remove(commentsTable, commentID); err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+
+	return nil
+}
+
+func (m MemoryCommentRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.CommentWithMetadata], error) {
+	mtx := tx.(*MemoryTx)
+	qspec := query.(collection.QuerySpec)
+
+	memoryQuery := cql.MemoryQuery[ticket.CommentWithMetadata]{Query: qspec, KeyField: "id"}
+	rows := memoryRows[ticket.CommentWithMetadata](mtx, commentsTable)
+	results, err := memoryQuery.Apply(rows)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, fmt.Errorf("executing query failed: %w", err)
+	}
+
+	size := uint64(len(results))
+	page := uint64(0)
+	if size > 0 {
+		page = qspec.Page
+	}
+	next, err := cql.NextCursor(qspec, "id", results)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, err
+	}
+
+	p := collection.Page[ticket.CommentWithMetadata]{
+		Results: results,
+		Page:    page,
+		Size:    size,
+		Next:    next,
+	}
+
+	if qspec.Count {
+		total, err := memoryQuery.Count(rows)
+		if err != nil {
+			return collection.Page[ticket.CommentWithMetadata]{}, fmt.Errorf("counting query failed: %w", err)
+		}
+		p = p.WithTotal(total, qspec.Size)
+	}
+
+	return p, nil
+}
+
+func (m MemoryCommentRepository) StartTx(ctx context.Context, readOnly bool) (repository.Tx, error) {
+	return m.store.StartTx(ctx, readOnly)
+}
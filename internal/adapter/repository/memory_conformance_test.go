@@ -0,0 +1,49 @@
+package repository_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	pkgrepository "github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/repository/repositorytest"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+func TestMemoryTicketRepositoryConformance(t *testing.T) {
+	repositorytest.Suite[ticket.TicketWithMetadata]{
+		NewRepository: func(t *testing.T) pkgrepository.Repository[ticket.TicketWithMetadata] {
+			return repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+		},
+		NewEntity: func(i int) ticket.TicketWithMetadata {
+			return ticket.TicketWithMetadata{Ticket: ticket.Ticket{Summary: fmt.Sprintf("summary %02d", i), Status: "open", Priority: ticket.DefaultPriority}}
+		},
+		Modify: func(t ticket.TicketWithMetadata) ticket.TicketWithMetadata {
+			t.Description = t.Description + " modified"
+			return t
+		},
+		ID:         func(t ticket.TicketWithMetadata) string { return t.ID },
+		Version:    func(t ticket.TicketWithMetadata) string { return t.Version },
+		Field:      "summary",
+		FieldValue: func(i int) any { return fmt.Sprintf("summary %02d", i) },
+	}.Run(t)
+}
+
+func TestMemoryCommentRepositoryConformance(t *testing.T) {
+	repositorytest.Suite[ticket.CommentWithMetadata]{
+		NewRepository: func(t *testing.T) pkgrepository.Repository[ticket.CommentWithMetadata] {
+			return repository.NewMemoryCommentRepository(repository.NewMemoryStore())
+		},
+		NewEntity: func(i int) ticket.CommentWithMetadata {
+			return ticket.CommentWithMetadata{Comment: ticket.Comment{TicketID: "mock-ticket", Author: "mock", Body: fmt.Sprintf("body %02d", i)}}
+		},
+		Modify: func(c ticket.CommentWithMetadata) ticket.CommentWithMetadata {
+			c.Body = c.Body + " modified"
+			return c
+		},
+		ID:         func(c ticket.CommentWithMetadata) string { return c.ID },
+		Version:    func(c ticket.CommentWithMetadata) string { return c.Version },
+		Field:      "body",
+		FieldValue: func(i int) any { return fmt.Sprintf("body %02d", i) },
+	}.Run(t)
+}
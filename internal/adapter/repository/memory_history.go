@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+// MemoryHistoryRepository is an append-only store of ticket history entries. It has no transactions
+// of its own and is always used within a transaction of the MemoryTicketRepository.
+type MemoryHistoryRepository struct {
+}
+
+func NewMemoryHistoryRepository() MemoryHistoryRepository {
+	return MemoryHistoryRepository{}
+}
+
+func (m MemoryHistoryRepository) Append(tx repository.Tx, h ticket.HistoryEntry) error {
+	mtx := tx.(*MemoryTx)
+	h.ID = newUUID()
+	h.ChangedAt = time.Now()
+
+	if err := mtx.put(historyTable, h.ID, h); err != nil {
+		return fmt.Errorf("insert failed: %w", err)
+	}
+
+	return nil
+}
+
+func (m MemoryHistoryRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.HistoryEntry], error) {
+	mtx := tx.(*MemoryTx)
+	qspec := query.(collection.QuerySpec)
+
+	memoryQuery := cql.MemoryQuery[ticket.HistoryEntry]{Query: qspec, KeyField: "id"}
+	rows := memoryRows[ticket.HistoryEntry](mtx, historyTable)
+	results, err := memoryQuery.Apply(rows)
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, fmt.Errorf("executing query failed: %w", err)
+	}
+
+	size := uint64(len(results))
+	page := uint64(0)
+	if size > 0 {
+		page = qspec.Page
+	}
+	next, err := cql.NextCursor(qspec, "id", results)
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, err
+	}
+
+	p := collection.Page[ticket.HistoryEntry]{
+		Results: results,
+		Page:    page,
+		Size:    size,
+		Next:    next,
+	}
+
+	if qspec.Count {
+		total, err := memoryQuery.Count(rows)
+		if err != nil {
+			return collection.Page[ticket.HistoryEntry]{}, fmt.Errorf("counting query failed: %w", err)
+		}
+		p = p.WithTotal(total, qspec.Size)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,220 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	pkgrepository "github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+func TestShouldNotSeeChangesAfterRollback(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	created, err := repo.Create(tx, mockTicket())
+	require.NoError(t, err)
+
+	// When
+	require.NoError(t, tx.Rollback())
+
+	// Then
+	tx, _ = repo.StartTx(context.Background(), true)
+	defer tx.Rollback()
+	_, err = repo.Read(tx, created.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldReadFromSnapshotTakenAtStart(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	readTx, _ := repo.StartTx(context.Background(), true)
+	defer readTx.Rollback()
+
+	// When
+	writeTx, _ := repo.StartTx(context.Background(), false)
+	created, err := repo.Create(writeTx, mockTicket())
+	require.NoError(t, err)
+	require.NoError(t, writeTx.Commit())
+
+	// Then
+	_, err = repo.Read(readTx, created.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldWaitForWriterToFinish(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	firstTx, _ := repo.StartTx(context.Background(), false)
+	started := make(chan pkgrepository.Tx)
+
+	// When
+	go func() {
+		secondTx, _ := repo.StartTx(context.Background(), false)
+		started <- secondTx
+	}()
+
+	// Then
+	select {
+	case <-started:
+		t.Fatal("second writer started before first finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, firstTx.Rollback())
+	select {
+	case secondTx := <-started:
+		assert.NoError(t, secondTx.Rollback())
+	case <-time.After(time.Second):
+		t.Fatal("second writer did not start after first finished")
+	}
+}
+
+func TestShouldReturnConflictOnStaleVersion(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	defer tx.Rollback()
+	created, _ := repo.Create(tx, mockTicket())
+	_, err := repo.Update(tx, created)
+	require.NoError(t, err)
+
+	// When
+	_, err = repo.Update(tx, created)
+
+	// Then
+	assert.ErrorAs(t, err, &pkgrepository.ConflictError{})
+}
+
+func TestShouldKeepCreatedMetadataOnUpdate(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	defer tx.Rollback()
+	t1 := mockTicket()
+	t1.CreatedBy = "alice"
+	created, err := repo.Create(tx, t1)
+	require.NoError(t, err)
+
+	// When
+	t2 := created
+	t2.CreatedAt = time.Time{}
+	t2.CreatedBy = "mallory"
+	t2.UpdatedBy = "bob"
+	updated, err := repo.Update(tx, t2)
+
+	// Then
+	require.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	assert.Equal(t, "alice", updated.CreatedBy)
+	assert.Equal(t, "bob", updated.UpdatedBy)
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+}
+
+func TestShouldHideTrashedTicket(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	defer tx.Rollback()
+	created, err := repo.Create(tx, mockTicket())
+	require.NoError(t, err)
+
+	// When
+	err = repo.Trash(tx, created.ID, created.Version, "alice")
+
+	// Then
+	require.NoError(t, err)
+	_, err = repo.Read(tx, created.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+	tickets, err := repo.Query(tx, collection.QuerySpec{})
+	require.NoError(t, err)
+	assert.Empty(t, tickets.Results)
+	trashed, err := repo.QueryTrash(tx, collection.QuerySpec{})
+	require.NoError(t, err)
+	require.Len(t, trashed.Results, 1)
+	assert.Equal(t, "alice", trashed.Results[0].DeletedBy)
+	assert.NotNil(t, trashed.Results[0].DeletedAt)
+}
+
+func TestShouldRestoreTrashedTicket(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	defer tx.Rollback()
+	created, _ := repo.Create(tx, mockTicket())
+	require.NoError(t, repo.Trash(tx, created.ID, "", "alice"))
+
+	// When
+	restored, err := repo.Restore(tx, created.ID, "bob")
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, "bob", restored.UpdatedBy)
+	_, err = repo.Read(tx, created.ID)
+	assert.NoError(t, err)
+	_, err = repo.ReadTrash(tx, created.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldPurgeTicketsTrashedBefore(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	defer tx.Rollback()
+	trashed, _ := repo.Create(tx, mockTicket())
+	kept, _ := repo.Create(tx, mockTicket())
+	require.NoError(t, repo.Trash(tx, trashed.ID, "", "alice"))
+
+	// When
+	purged, err := repo.Purge(tx, time.Now().Add(time.Second))
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{trashed.ID}, purged)
+	_, err = repo.ReadTrash(tx, trashed.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+	_, err = repo.Read(tx, kept.ID)
+	assert.NoError(t, err)
+}
+
+func TestShouldNotPurgeTicketsTrashedAfter(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), false)
+	defer tx.Rollback()
+	created, _ := repo.Create(tx, mockTicket())
+	require.NoError(t, repo.Trash(tx, created.ID, "", "alice"))
+
+	// When
+	purged, err := repo.Purge(tx, time.Now().Add(-time.Hour))
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+	_, err = repo.ReadTrash(tx, created.ID)
+	assert.NoError(t, err)
+}
+
+func TestShouldNotWriteInReadOnlyTx(t *testing.T) {
+	// Given
+	repo := repository.NewMemoryTicketRepository(repository.NewMemoryStore())
+	tx, _ := repo.StartTx(context.Background(), true)
+	defer tx.Rollback()
+
+	// When
+	_, err := repo.Create(tx, mockTicket())
+
+	// Then
+	assert.Error(t, err)
+}
+
+func mockTicket() ticket.TicketWithMetadata {
+	return ticket.TicketWithMetadata{Ticket: ticket.Ticket{Summary: "mock summary", Status: "open", Priority: ticket.DefaultPriority}}
+}
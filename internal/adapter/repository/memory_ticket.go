@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+const (
+	ticketsTable  = "tickets"
+	commentsTable = "comments"
+	historyTable  = "ticket_history"
+)
+
+// ticketOrderedFields are the ticket fields ordered as by their SQL enum types.
+var ticketOrderedFields = map[string][]string{"priority": ticket.Priorities}
+
+type MemoryTicketRepository struct {
+	store *MemoryStore
+}
+
+var _ repository.Repository[ticket.TicketWithMetadata] = (*MemoryTicketRepository)(nil)
+
+func NewMemoryTicketRepository(store *MemoryStore) MemoryTicketRepository {
+	return MemoryTicketRepository{store: store}
+}
+
+func (m MemoryTicketRepository) Create(tx repository.Tx, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
+	mtx := tx.(*MemoryTx)
+	t.ID = newUUID()
+	t.Version = "0"
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	t.DeletedAt = nil
+	t.DeletedBy = ""
+
+	if err := mtx.put(ticketsTable, t.ID, t); err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("insert failed: %w", err)
+	}
+
+	return t, nil
+}
+
+// Read finds a ticket that is not in the trash.
+func (m MemoryTicketRepository) Read(tx repository.Tx, ticketID string) (ticket.TicketWithMetadata, error) {
+	return m.read(tx.(*MemoryTx), ticketID, false)
+}
+
+// ReadTrash finds a ticket in the trash.
+func (m MemoryTicketRepository) ReadTrash(tx repository.Tx, ticketID string) (ticket.TicketWithMetadata, error) {
+	return m.read(tx.(*MemoryTx), ticketID, true)
+}
+
+// read finds a ticket in or not in the trash.
+func (m MemoryTicketRepository) read(mtx *MemoryTx, ticketID string, trashed bool) (ticket.TicketWithMetadata, error) {
+	row, ok := mtx.get(ticketsTable, ticketID)
+	if !ok || (row.(ticket.TicketWithMetadata).DeletedAt != nil) != trashed {
+		if trashed {
+			return ticket.TicketWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found in trash", ticketID)}
+		}
+		return ticket.TicketWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found", ticketID)}
+	}
+
+	return row.(ticket.TicketWithMetadata), nil
+}
+
+func (m MemoryTicketRepository) Update(tx repository.Tx, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
+	mtx := tx.(*MemoryTx)
+	current, err := m.Read(tx, t.Metadata.ID)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("read ticket failed: %w", err)
+	}
+
+	if current.Version != t.Version {
+		return ticket.TicketWithMetadata{}, repository.ConflictError{Message: "version conflict"}
+	}
+
+	t.Version, err = nextVersion(current.Version)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+	t.CreatedAt = current.CreatedAt
+	t.CreatedBy = current.CreatedBy
+	t.UpdatedAt = time.Now()
+	t.DeletedAt = nil
+	t.DeletedBy = ""
+
+	if err := mtx.put(ticketsTable, t.ID, t); err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("update failed: %w", err)
+	}
+
+	return t, nil
+}
+
+// Delete permanently deletes the ticket, whether or not it is in the trash, and its comments.
+func (m MemoryTicketRepository) Delete(tx repository.Tx, ticketID string, version string) error {
+	mtx := tx.(*MemoryTx)
+	row, ok := mtx.get(ticketsTable, ticketID)
+	if !ok {
+		return repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found", ticketID)}
+	}
+	current := row.(ticket.TicketWithMetadata)
+
+	if version != "" && current.Version != version {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	return m.remove(mtx, ticketID)
+}
+
+// Trash moves the ticket to the trash, recording the time and the principal that deleted it.
+func (m MemoryTicketRepository) Trash(tx repository.Tx, ticketID string, version string, deletedBy string) error {
+	mtx := tx.(*MemoryTx)
+	current, err := m.Read(tx, ticketID)
+	if err != nil {
+		return fmt.Errorf("read ticket failed: %w", err)
+	}
+
+	if version != "" && current.Version != version {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	deletedAt := time.Now()
+	current.DeletedAt = &deletedAt
+	current.DeletedBy = deletedBy
+	current.Version, err = nextVersion(current.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := mtx.put(ticketsTable, ticketID, current); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+
+	return nil
+}
+
+// Restore moves the ticket out of the trash.
+func (m MemoryTicketRepository) Restore(tx repository.Tx, ticketID string, restoredBy string) (ticket.TicketWithMetadata, error) {
+	mtx := tx.(*MemoryTx)
+	current, err := m.ReadTrash(tx, ticketID)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("read ticket failed: %w", err)
+	}
+
+	current.DeletedAt = nil
+	current.DeletedBy = ""
+	current.UpdatedAt = time.Now()
+	current.UpdatedBy = restoredBy
+	current.Version, err = nextVersion(current.Version)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	if err := mtx.put(ticketsTable, ticketID, current); err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("update failed: %w", err)
+	}
+
+	return current, nil
+}
+
+// Purge permanently deletes the tickets moved to the trash before the given time, and their comments.
+func (m MemoryTicketRepository) Purge(tx repository.Tx, before time.Time) ([]string, error) {
+	mtx := tx.(*MemoryTx)
+	purged := []string{}
+	for _, t := range m.rows(mtx, true) {
+		if t.DeletedAt.Before(before) {
+			if err := m.remove(mtx, t.ID); err != nil {
+				return nil, err
+			}
+			purged = append(purged, t.ID)
+		}
+	}
+
+	return purged, nil
+}
+
+// remove deletes the ticket and its comments.
+func (m MemoryTicketRepository) remove(mtx *MemoryTx, ticketID string) error {
+	if err := mtx.remove(ticketsTable, ticketID); err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+
+	for _, c := range memoryRows[ticket.CommentWithMetadata](mtx, commentsTable) {
+		if c.TicketID == ticketID {
+			if err := mtx.remove(commentsTable, c.ID); err != nil {
+				return fmt.Errorf("delete failed: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Query finds tickets that are not in the trash.
+func (m MemoryTicketRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.TicketWithMetadata], error) {
+	return m.query(tx.(*MemoryTx), query.(collection.QuerySpec), false)
+}
+
+// QueryTrash finds tickets in the trash.
+func (m MemoryTicketRepository) QueryTrash(tx repository.Tx, query repository.Query) (collection.Page[ticket.TicketWithMetadata], error) {
+	return m.query(tx.(*MemoryTx), query.(collection.QuerySpec), true)
+}
+
+// query finds tickets in or not in the trash.
+func (m MemoryTicketRepository) query(mtx *MemoryTx, qspec collection.QuerySpec, trashed bool) (collection.Page[ticket.TicketWithMetadata], error) {
+
+	memoryQuery := cql.MemoryQuery[ticket.TicketWithMetadata]{
+		Query:        qspec,
+		KeyField:     "id",
+		SearchFields: []string{"summary", "description"},
+		Ordered:      ticketOrderedFields,
+	}
+	rows := m.rows(mtx, trashed)
+	results, err := memoryQuery.Apply(rows)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("executing query failed: %w", err)
+	}
+	var matches []*collection.Match
+	if qspec.Search != "" {
+		matches = make([]*collection.Match, len(results))
+		for i := range results {
+			if matches[i], err = memoryQuery.Match(results[i]); err != nil {
+				return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("executing query failed: %w", err)
+			}
+		}
+	}
+
+	size := uint64(len(results))
+	page := uint64(0)
+	if size > 0 {
+		page = qspec.Page
+	}
+	next, err := cql.NextCursor(qspec, "id", results)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, err
+	}
+
+	p := collection.Page[ticket.TicketWithMetadata]{
+		Results: results,
+		Page:    page,
+		Size:    size,
+		Next:    next,
+		Matches: matches,
+	}
+
+	if qspec.Count {
+		total, err := memoryQuery.Count(rows)
+		if err != nil {
+			return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("counting query failed: %w", err)
+		}
+		p = p.WithTotal(total, qspec.Size)
+	}
+
+	return p, nil
+}
+
+// Facets counts the tickets matching the filters of the facet query with each value of each of its
+// fields. Tickets in the trash are not counted.
+func (m MemoryTicketRepository) Facets(tx repository.Tx, query collection.FacetSpec) (collection.Facets, error) {
+	mtx := tx.(*MemoryTx)
+
+	memoryQuery := cql.MemoryQuery[ticket.TicketWithMetadata]{Query: collection.QuerySpec{Filters: query.Filters}, Ordered: ticketOrderedFields}
+	rows := m.rows(mtx, false)
+
+	facets := collection.Facets{Facets: []collection.Facet{}}
+	for _, field := range query.By {
+		buckets, err := memoryQuery.Group(rows, field)
+		if err != nil {
+			return collection.Facets{}, fmt.Errorf("grouping query failed: %w", err)
+		}
+		facets.Facets = append(facets.Facets, collection.Facet{Field: field, Buckets: buckets})
+	}
+
+	return facets, nil
+}
+
+func (m MemoryTicketRepository) StartTx(ctx context.Context, readOnly bool) (repository.Tx, error) {
+	return m.store.StartTx(ctx, readOnly)
+}
+
+// rows returns the tickets in or not in the trash.
+func (m MemoryTicketRepository) rows(mtx *MemoryTx, trashed bool) []ticket.TicketWithMetadata {
+	rows := []ticket.TicketWithMetadata{}
+	for _, t := range memoryRows[ticket.TicketWithMetadata](mtx, ticketsTable) {
+		if (t.DeletedAt != nil) == trashed {
+			rows = append(rows, t)
+		}
+	}
+	return rows
+}
+
+// nextVersion returns the version following the given version.
+func nextVersion(version string) (string, error) {
+	v, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid version %s: %w", version, err)
+	}
+	return strconv.FormatUint(v+1, 10), nil
+}
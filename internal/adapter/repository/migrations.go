@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+	"log"
+
+	"github.com/grantjforrester/go-ticket/pkg/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// NewSQLMigrator creates a migrator for the schema of the ticket database.
+func NewSQLMigrator(pool *sql.DB) migrate.Migrator {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	migrator, err := migrate.NewMigrator(pool, files)
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	return migrator
+}
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS increment_version();
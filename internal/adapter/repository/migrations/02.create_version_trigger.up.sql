@@ -1,5 +1,3 @@
-\connect tickets
-
 CREATE OR REPLACE FUNCTION increment_version()
   RETURNS TRIGGER
 AS
@@ -9,4 +7,4 @@ BEGIN
   RETURN new;
 END;
 $body$
-LANGUAGE plpgsql;
\ No newline at end of file
+LANGUAGE plpgsql;
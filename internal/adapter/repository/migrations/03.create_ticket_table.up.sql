@@ -1,6 +1,4 @@
-\connect tickets
-
-CREATE TABLE tickets
+CREATE TABLE IF NOT EXISTS tickets
 (
     id UUID PRIMARY KEY,
     version NUMERIC NOT NULL DEFAULT 0,
@@ -9,6 +7,8 @@ CREATE TABLE tickets
     status VARCHAR(50) NOT NULL
 );
 
+DROP TRIGGER IF EXISTS version_trigger ON tickets;
+
 CREATE TRIGGER version_trigger
    BEFORE UPDATE ON tickets
-   FOR EACH ROW EXECUTE PROCEDURE increment_version();
\ No newline at end of file
+   FOR EACH ROW EXECUTE PROCEDURE increment_version();
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS comments
+(
+    id UUID PRIMARY KEY,
+    version NUMERIC NOT NULL DEFAULT 0,
+    ticket_id UUID NOT NULL REFERENCES tickets (id) ON DELETE CASCADE,
+    author VARCHAR(100) NOT NULL,
+    body VARCHAR(5000) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS comments_ticket_id_idx ON comments (ticket_id);
+
+DROP TRIGGER IF EXISTS version_trigger ON comments;
+
+CREATE TRIGGER version_trigger
+   BEFORE UPDATE ON comments
+   FOR EACH ROW EXECUTE PROCEDURE increment_version();
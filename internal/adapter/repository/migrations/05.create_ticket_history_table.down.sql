@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ticket_history;
+
+DROP FUNCTION IF EXISTS prevent_modification();
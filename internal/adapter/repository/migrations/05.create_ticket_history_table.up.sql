@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS ticket_history
+(
+    id UUID PRIMARY KEY,
+    ticket_id UUID NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    changed_by VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    changes JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ticket_history_ticket_id_idx ON ticket_history (ticket_id, changed_at);
+
+CREATE OR REPLACE FUNCTION prevent_modification()
+  RETURNS TRIGGER
+AS
+$body$
+BEGIN
+  RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$body$
+LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS append_only_trigger ON ticket_history;
+
+CREATE TRIGGER append_only_trigger
+   BEFORE UPDATE OR DELETE ON ticket_history
+   FOR EACH ROW EXECUTE PROCEDURE prevent_modification();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tickets_search_idx;
+
+ALTER TABLE tickets DROP COLUMN IF EXISTS search;
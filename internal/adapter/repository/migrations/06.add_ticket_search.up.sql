@@ -0,0 +1,7 @@
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS search TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(summary, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS tickets_search_idx ON tickets USING GIN (search);
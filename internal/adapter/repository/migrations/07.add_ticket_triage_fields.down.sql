@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS tickets_assignee_idx;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS severity,
+    DROP COLUMN IF EXISTS assignee,
+    DROP COLUMN IF EXISTS reporter;
+
+DROP TYPE IF EXISTS ticket_priority;
@@ -0,0 +1,15 @@
+DO $$
+BEGIN
+  CREATE TYPE ticket_priority AS ENUM ('low', 'medium', 'high', 'critical');
+EXCEPTION
+  WHEN duplicate_object THEN NULL;
+END
+$$;
+
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS priority ticket_priority NOT NULL DEFAULT 'medium',
+    ADD COLUMN IF NOT EXISTS severity VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS assignee VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS reporter VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS tickets_assignee_idx ON tickets (assignee);
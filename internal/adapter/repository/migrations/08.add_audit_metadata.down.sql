@@ -0,0 +1,11 @@
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS updated_by;
+
+DROP INDEX IF EXISTS tickets_updated_at_idx;
+
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS updated_by;
@@ -0,0 +1,11 @@
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS created_by VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS updated_by VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS tickets_updated_at_idx ON tickets (updated_at);
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS created_by VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS updated_by VARCHAR(100);
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+// countSQL returns the number of rows matching the filters of the query.
+func countSQL(ptx *sql.Tx, query cql.SQLQuery) (uint64, error) {
+	qry, args, err := query.ToCountSQL()
+	if err != nil {
+		return 0, fmt.Errorf("building sql count query failed: %w", err)
+	}
+
+	var count uint64
+	if err := ptx.QueryRow(qry, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("executing count query failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// groupSQL returns the number of rows matching the filters of the query with each value of the field.
+// Values of types unknown to the driver, such as enums, are returned as text.
+func groupSQL(ptx *sql.Tx, query cql.SQLQuery, field string) ([]collection.Bucket, error) {
+	qry, args, err := query.ToGroupSQL(field)
+	if err != nil {
+		return nil, fmt.Errorf("building sql group query failed: %w", err)
+	}
+
+	rows, err := ptx.Query(qry, args...)
+	if err != nil {
+		return nil, fmt.Errorf("executing group query failed: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []collection.Bucket{}
+	for rows.Next() {
+		b := collection.Bucket{}
+		if err := rows.Scan(&b.Value, &b.Count); err != nil {
+			return nil, fmt.Errorf("error reading row: %w", err)
+		}
+		if bytes, ok := b.Value.([]byte); ok {
+			b.Value = string(bytes)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// nullString is a string column that may be null. Null is scanned as "".
+type nullString string
+
+func (n *nullString) Scan(value any) error {
+	ns := sql.NullString{}
+	if err := ns.Scan(value); err != nil {
+		return err
+	}
+	*n = nullString(ns.String)
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	pkgrepository "github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/repository/repositorytest"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+// TestSQLTicketRepositoryConformance runs against the database given by the DB_* environment
+// variables. It is skipped when DB_HOST is not set. All tickets are removed from the database.
+func TestSQLTicketRepositoryConformance(t *testing.T) {
+	pool := testPool(t)
+
+	repositorytest.Suite[ticket.TicketWithMetadata]{
+		NewRepository: func(t *testing.T) pkgrepository.Repository[ticket.TicketWithMetadata] {
+			_, err := pool.Exec("TRUNCATE tickets CASCADE")
+			require.NoError(t, err)
+			return repository.NewSQLTicketRepository(pool)
+		},
+		NewEntity: func(i int) ticket.TicketWithMetadata {
+			return ticket.TicketWithMetadata{Ticket: ticket.Ticket{Summary: fmt.Sprintf("summary %02d", i), Status: "open", Priority: ticket.DefaultPriority}}
+		},
+		Modify: func(t ticket.TicketWithMetadata) ticket.TicketWithMetadata {
+			t.Description = t.Description + " modified"
+			return t
+		},
+		ID:         func(t ticket.TicketWithMetadata) string { return t.ID },
+		Version:    func(t ticket.TicketWithMetadata) string { return t.Version },
+		Field:      "summary",
+		FieldValue: func(i int) any { return fmt.Sprintf("summary %02d", i) },
+	}.Run(t)
+}
+
+// TestSQLMigratorStatusShouldNotChangeDatabase runs against the database given by the DB_*
+// environment variables in a new schema. It is skipped when DB_HOST is not set.
+func TestSQLMigratorStatusShouldNotChangeDatabase(t *testing.T) {
+	// Given
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST not set")
+	}
+	pool := repository.NewSQLConnectionPool(envConfig{})
+	t.Cleanup(func() { pool.Close() })
+	pool.SetMaxOpenConns(1)
+	_, err := pool.Exec("CREATE SCHEMA migrator_status_test")
+	require.NoError(t, err)
+	t.Cleanup(func() { pool.Exec("DROP SCHEMA migrator_status_test CASCADE") })
+	_, err = pool.Exec("SET search_path TO migrator_status_test")
+	require.NoError(t, err)
+
+	// When
+	statuses, err := repository.NewSQLMigrator(pool).Status(context.Background())
+
+	// Then
+	require.NoError(t, err)
+	for _, s := range statuses {
+		require.Nil(t, s.AppliedAt)
+	}
+	var exists bool
+	require.NoError(t, pool.QueryRow("SELECT to_regclass('migrator_status_test.schema_migrations') IS NOT NULL").Scan(&exists))
+	require.False(t, exists)
+}
+
+func testPool(t *testing.T) *sql.DB {
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST not set")
+	}
+
+	pool := repository.NewSQLConnectionPool(envConfig{})
+	t.Cleanup(func() { pool.Close() })
+	_, err := repository.NewSQLMigrator(pool).Up(context.Background())
+	require.NoError(t, err)
+
+	return pool
+}
+
+// envConfig provides configuration from environment variables named after the upper case key.
+type envConfig struct{}
+
+func (envConfig) Get(key string) any {
+	return os.Getenv(envKey(key))
+}
+
+func (envConfig) GetString(key string) string {
+	return os.Getenv(envKey(key))
+}
+
+func (envConfig) GetBool(key string) bool {
+	b, _ := strconv.ParseBool(os.Getenv(envKey(key)))
+	return b
+}
+
+func (envConfig) GetInt(key string) int {
+	i, _ := strconv.Atoi(os.Getenv(envKey(key)))
+	return i
+}
+
+func envKey(key string) string {
+	return strings.ToUpper(key)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/grantjforrester/go-ticket/pkg/collection"
 	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
@@ -17,6 +19,9 @@ type SQLTicketRepository struct {
 
 var _ repository.Repository[ticket.TicketWithMetadata] = (*SQLTicketRepository)(nil)
 
+var ticketFields = []string{"id", "version", "summary", "description", "status", "priority", "severity", "assignee", "reporter",
+	"created_at", "updated_at", "created_by", "updated_by", "deleted_at", "deleted_by"}
+
 func NewSQLTicketRepository(pool *sql.DB) SQLTicketRepository {
 	return SQLTicketRepository{connectionPool: pool}
 }
@@ -25,9 +30,12 @@ func (s SQLTicketRepository) Create(tx repository.Tx, t ticket.TicketWithMetadat
 	ptx := tx.(*sql.Tx)
 	var uuid string
 
-	err := ptx.QueryRow(`INSERT INTO tickets (id, version, summary, description, status)
-			  				VALUES (uuid_generate_v4(), 0, $1, $2, $3)
-			  				RETURNING id`, t.Summary, t.Description, t.Status).Scan(&uuid)
+	err := ptx.QueryRow(`INSERT INTO tickets (id, version, summary, description, status, priority, severity, assignee, reporter,
+			  					created_by, updated_by)
+			  				VALUES (uuid_generate_v4(), 0, $1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''),
+			  					NULLIF($8, ''), NULLIF($9, ''))
+			  				RETURNING id`, t.Summary, t.Description, t.Status, t.Priority, t.Severity, t.Assignee, t.Reporter,
+		t.CreatedBy, t.UpdatedBy).Scan(&uuid)
 	if err != nil {
 		return ticket.TicketWithMetadata{}, fmt.Errorf("insert statement failed: %w", err)
 	}
@@ -37,21 +45,35 @@ func (s SQLTicketRepository) Create(tx repository.Tx, t ticket.TicketWithMetadat
 	return createdTicket, err
 }
 
+// Read finds a ticket that is not in the trash.
 func (s SQLTicketRepository) Read(tx repository.Tx, ticketID string) (ticket.TicketWithMetadata, error) {
-	ptx := tx.(*sql.Tx)
-	row := ptx.QueryRow(`SELECT id, version, summary, description, status 
+	t, err := s.read(tx.(*sql.Tx), ticketID, "AND deleted_at IS NULL")
+	if err == sql.ErrNoRows {
+		return ticket.TicketWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found", ticketID)}
+	}
+	return t, err
+}
+
+// ReadTrash finds a ticket in the trash.
+func (s SQLTicketRepository) ReadTrash(tx repository.Tx, ticketID string) (ticket.TicketWithMetadata, error) {
+	t, err := s.read(tx.(*sql.Tx), ticketID, "AND deleted_at IS NOT NULL")
+	if err == sql.ErrNoRows {
+		return ticket.TicketWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found in trash", ticketID)}
+	}
+	return t, err
+}
+
+// read finds a ticket matching the condition. Returns sql.ErrNoRows if there is none.
+func (s SQLTicketRepository) read(ptx *sql.Tx, ticketID string, condition string) (ticket.TicketWithMetadata, error) {
+	row := ptx.QueryRow(`SELECT `+strings.Join(ticketFields, ", ")+`
 						 	FROM tickets
-							WHERE id = $1`, ticketID)
+							WHERE id = $1 `+condition, ticketID)
 
 	t := ticket.TicketWithMetadata{}
-	switch err := row.Scan(&t.ID, &t.Version, &t.Summary, &t.Description, &t.Status); err {
-	case nil:
-		return t, nil
-	case sql.ErrNoRows:
-		return ticket.TicketWithMetadata{}, NotFoundError{Message: fmt.Sprintf("no ticket with id %s found", ticketID)}
-	default:
+	if err := row.Scan(scanTicketFields(&t, ticketFields)...); err != nil {
 		return ticket.TicketWithMetadata{}, err
 	}
+	return t, nil
 }
 
 func (s SQLTicketRepository) Update(tx repository.Tx, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
@@ -62,10 +84,13 @@ func (s SQLTicketRepository) Update(tx repository.Tx, t ticket.TicketWithMetadat
 	}
 
 	res, err := ptx.Exec(`UPDATE tickets
-							SET summary = $3, description = $4, status = $5
+							SET summary = $3, description = $4, status = $5, priority = $6,
+								severity = NULLIF($7, ''), assignee = NULLIF($8, ''), reporter = NULLIF($9, ''),
+								updated_at = now(), updated_by = NULLIF($10, '')
 							WHERE id = $1
-							AND version = $2`,
-		t.ID, t.Version, t.Summary, t.Description, t.Status)
+							AND version = $2
+							AND deleted_at IS NULL`,
+		t.ID, t.Version, t.Summary, t.Description, t.Status, t.Priority, t.Severity, t.Assignee, t.Reporter, t.UpdatedBy)
 	if err != nil {
 		return ticket.TicketWithMetadata{}, fmt.Errorf("update statement failed: %w", err)
 	}
@@ -75,31 +100,154 @@ func (s SQLTicketRepository) Update(tx repository.Tx, t ticket.TicketWithMetadat
 		return ticket.TicketWithMetadata{}, fmt.Errorf("count of updated rows failed: %w", err)
 	}
 	if rowCount != 1 {
-		return ticket.TicketWithMetadata{}, ConflictError{Message: "version conflict"}
+		return ticket.TicketWithMetadata{}, repository.ConflictError{Message: "version conflict"}
 	}
 
 	return s.Read(tx, t.Metadata.ID)
 }
 
-func (s SQLTicketRepository) Delete(tx repository.Tx, ticketID string) error {
+// Delete permanently deletes the ticket, whether or not it is in the trash, and its comments.
+func (s SQLTicketRepository) Delete(tx repository.Tx, ticketID string, version string) error {
 	ptx := tx.(*sql.Tx)
-	_, err := ptx.Exec(`DELETE FROM tickets WHERE id = $1`, ticketID)
+	_, err := s.read(ptx, ticketID, "")
+	if err == sql.ErrNoRows {
+		return repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found", ticketID)}
+	}
+	if err != nil {
+		return fmt.Errorf("read ticket failed: %w", err)
+	}
+
+	var res sql.Result
+	if version == "" {
+		res, err = ptx.Exec(`DELETE FROM tickets WHERE id = $1`, ticketID)
+	} else {
+		res, err = ptx.Exec(`DELETE FROM tickets
+							WHERE id = $1
+							AND version = $2`, ticketID, version)
+	}
 	if err != nil {
 		return fmt.Errorf("delete statement failed: %w", err)
 	}
 
+	rowCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("count of deleted rows failed: %w", err)
+	}
+	if rowCount != 1 {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
 	return nil
 }
 
-func (s SQLTicketRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.TicketWithMetadata], error) {
+// Trash moves the ticket to the trash, recording the time and the principal that deleted it.
+func (s SQLTicketRepository) Trash(tx repository.Tx, ticketID string, version string, deletedBy string) error {
 	ptx := tx.(*sql.Tx)
-	qspec := query.(collection.QuerySpec)
+	_, err := s.Read(tx, ticketID)
+	if err != nil {
+		return fmt.Errorf("read ticket failed: %w", err)
+	}
+
+	var res sql.Result
+	if version == "" {
+		res, err = ptx.Exec(`UPDATE tickets
+							SET deleted_at = now(), deleted_by = NULLIF($2, '')
+							WHERE id = $1
+							AND deleted_at IS NULL`, ticketID, deletedBy)
+	} else {
+		res, err = ptx.Exec(`UPDATE tickets
+							SET deleted_at = now(), deleted_by = NULLIF($3, '')
+							WHERE id = $1
+							AND version = $2
+							AND deleted_at IS NULL`, ticketID, version, deletedBy)
+	}
+	if err != nil {
+		return fmt.Errorf("update statement failed: %w", err)
+	}
+
+	rowCount, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("count of updated rows failed: %w", err)
+	}
+	if rowCount != 1 {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	return nil
+}
+
+// Restore moves the ticket out of the trash.
+func (s SQLTicketRepository) Restore(tx repository.Tx, ticketID string, restoredBy string) (ticket.TicketWithMetadata, error) {
+	ptx := tx.(*sql.Tx)
+	res, err := ptx.Exec(`UPDATE tickets
+							SET deleted_at = NULL, deleted_by = NULL, updated_at = now(), updated_by = NULLIF($2, '')
+							WHERE id = $1
+							AND deleted_at IS NOT NULL`, ticketID, restoredBy)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("update statement failed: %w", err)
+	}
+
+	rowCount, err := res.RowsAffected()
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("count of updated rows failed: %w", err)
+	}
+	if rowCount != 1 {
+		return ticket.TicketWithMetadata{}, repository.NotFoundError{Message: fmt.Sprintf("no ticket with id %s found in trash", ticketID)}
+	}
+
+	return s.Read(tx, ticketID)
+}
+
+// Purge permanently deletes the tickets moved to the trash before the given time, and their comments.
+func (s SQLTicketRepository) Purge(tx repository.Tx, before time.Time) ([]string, error) {
+	ptx := tx.(*sql.Tx)
+	rows, err := ptx.Query(`DELETE FROM tickets
+							WHERE deleted_at < $1
+							RETURNING id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("delete statement failed: %w", err)
+	}
+	defer rows.Close()
+
+	purged := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error reading row: %w", err)
+		}
+		purged = append(purged, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return purged, nil
+}
+
+// Query finds tickets that are not in the trash.
+func (s SQLTicketRepository) Query(tx repository.Tx, query repository.Query) (collection.Page[ticket.TicketWithMetadata], error) {
+	return s.query(tx.(*sql.Tx), query.(collection.QuerySpec), false)
+}
+
+// QueryTrash finds tickets in the trash.
+func (s SQLTicketRepository) QueryTrash(tx repository.Tx, query repository.Query) (collection.Page[ticket.TicketWithMetadata], error) {
+	return s.query(tx.(*sql.Tx), query.(collection.QuerySpec), true)
+}
+
+// query finds tickets in or not in the trash.
+func (s SQLTicketRepository) query(ptx *sql.Tx, qspec collection.QuerySpec, trashed bool) (collection.Page[ticket.TicketWithMetadata], error) {
+	qspec.Filters = withTrashFilter(qspec.Filters, trashed)
 	results := []ticket.TicketWithMetadata{}
-	qry, args, err := cql.SQLQuery{
-		Fields: []string{"id", "version", "summary", "description", "status"},
-		Table:  "tickets",
-		Query:  qspec,
-	}.ToSQL()
+	var matches []*collection.Match
+	sqlQuery := cql.SQLQuery{
+		Fields:       cql.SelectFields(qspec, ticketFields, "id", "version"),
+		Table:        "tickets",
+		Query:        qspec,
+		KeyField:     "id",
+		SearchVector: "search",
+		SearchFields: []string{"summary", "description"},
+	}
+	qry, args, err := sqlQuery.ToSQL()
 	if err != nil {
 		return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("building sql query failed): %w", err)
 	}
@@ -113,12 +261,20 @@ func (s SQLTicketRepository) Query(tx repository.Tx, query repository.Query) (co
 
 	for rows.Next() {
 		t := ticket.TicketWithMetadata{}
-		err := rows.Scan(&t.ID, &t.Version, &t.Summary, &t.Description, &t.Status)
+		dest := scanTicketFields(&t, sqlQuery.Fields)
+		match := collection.Match{}
+		if qspec.Search != "" {
+			dest = append(dest, &match.Rank, &match.Snippet)
+		}
+		err := rows.Scan(dest...)
 		if err != nil {
 			return collection.Page[ticket.TicketWithMetadata]{},
 				fmt.Errorf("error reading row: %w", err)
 		}
 		results = append(results, t)
+		if qspec.Search != "" {
+			matches = append(matches, &match)
+		}
 	}
 
 	size := uint64(len(results))
@@ -126,11 +282,49 @@ func (s SQLTicketRepository) Query(tx repository.Tx, query repository.Query) (co
 	if size > 0 {
 		page = qspec.Page
 	}
-	return collection.Page[ticket.TicketWithMetadata]{
+	next, err := cql.NextCursor(qspec, "id", results)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, err
+	}
+
+	p := collection.Page[ticket.TicketWithMetadata]{
 		Results: results,
 		Page:    page,
 		Size:    size,
-	}, nil
+		Next:    next,
+		Matches: matches,
+	}
+
+	if qspec.Count {
+		total, err := countSQL(ptx, sqlQuery)
+		if err != nil {
+			return collection.Page[ticket.TicketWithMetadata]{}, err
+		}
+		p = p.WithTotal(total, qspec.Size)
+	}
+
+	return p, nil
+}
+
+// Facets counts the tickets matching the filters of the facet query with each value of each of its
+// fields. Tickets in the trash are not counted.
+func (s SQLTicketRepository) Facets(tx repository.Tx, query collection.FacetSpec) (collection.Facets, error) {
+	ptx := tx.(*sql.Tx)
+	sqlQuery := cql.SQLQuery{
+		Table: "tickets",
+		Query: collection.QuerySpec{Filters: withTrashFilter(query.Filters, false)},
+	}
+
+	facets := collection.Facets{Facets: []collection.Facet{}}
+	for _, field := range query.By {
+		buckets, err := groupSQL(ptx, sqlQuery, field)
+		if err != nil {
+			return collection.Facets{}, err
+		}
+		facets.Facets = append(facets.Facets, collection.Facet{Field: field, Buckets: buckets})
+	}
+
+	return facets, nil
 }
 
 func (s SQLTicketRepository) StartTx(ctx context.Context, readOnly bool) (repository.Tx, error) {
@@ -141,3 +335,37 @@ func (s SQLTicketRepository) StartTx(ctx context.Context, readOnly bool) (reposi
 	}
 	return tx, nil
 }
+
+// withTrashFilter returns the filters with a filter for tickets in or not in the trash.
+func withTrashFilter(filters []collection.Expr, trashed bool) []collection.Expr {
+	trash := collection.FilterExpr{Field: "deleted_at", Operator: cql.OpIsNull, Value: !trashed}
+	return append([]collection.Expr{trash}, filters...)
+}
+
+// scanTicketFields returns the destinations in the ticket of the values of the given fields.
+func scanTicketFields(t *ticket.TicketWithMetadata, fields []string) []any {
+	destinations := map[string]any{
+		"id":          &t.ID,
+		"version":     &t.Version,
+		"summary":     &t.Summary,
+		"description": &t.Description,
+		"status":      &t.Status,
+		"priority":    &t.Priority,
+		"severity":    (*nullString)(&t.Severity),
+		"assignee":    (*nullString)(&t.Assignee),
+		"reporter":    (*nullString)(&t.Reporter),
+		"created_at":  &t.CreatedAt,
+		"updated_at":  &t.UpdatedAt,
+		"created_by":  (*nullString)(&t.CreatedBy),
+		"updated_by":  (*nullString)(&t.UpdatedBy),
+		"deleted_at":  &t.DeletedAt,
+		"deleted_by":  (*nullString)(&t.DeletedBy),
+	}
+
+	dest := make([]any, len(fields))
+	for i, f := range fields {
+		dest[i] = destinations[f]
+	}
+
+	return dest
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+// MaxBatchOperations is the most operations accepted in a batch.
+var MaxBatchOperations = 1000
+
+// TicketOperation is a create, update or delete of a ticket in a batch. A delete only uses the id and
+// version of the ticket, and is not checked against the version if it is "".
+type TicketOperation struct {
+	Action ticket.Action
+	Ticket ticket.TicketWithMetadata
+}
+
+// TicketOperationResult is the outcome of an operation in a batch. Ticket is the created or updated
+// ticket if the operation succeeded, and Err is set if it failed.
+type TicketOperationResult struct {
+	Ticket ticket.TicketWithMetadata
+	Err    error
+}
+
+// BatchTickets applies the operations in order, authorizing each operation, and returns the result
+// of each operation. If atomic, the operations are applied in one transaction and either all succeed
+// or none are applied, with operations other than the first that failed returning BatchAbortedError.
+// Otherwise, each operation is applied in its own transaction regardless of the others.
+func (svc TicketService) BatchTickets(context context.Context, operations []TicketOperation, atomic bool) ([]TicketOperationResult, error) {
+	if len(operations) > MaxBatchOperations {
+		return nil, RequestError{Message: fmt.Sprintf("too many operations: at most %d allowed", MaxBatchOperations)}
+	}
+
+	for i, op := range operations {
+		if err := checkOperation(op); err != nil {
+			return nil, RequestError{Message: fmt.Sprintf("invalid operation %d: %s", i, err)}
+		}
+	}
+
+	if atomic {
+		return svc.batchAtomic(context, operations)
+	}
+
+	results := make([]TicketOperationResult, len(operations))
+	for i, op := range operations {
+		switch op.Action {
+		case ticket.ActionCreate:
+			results[i].Ticket, results[i].Err = svc.CreateTicket(context, op.Ticket)
+		case ticket.ActionUpdate:
+			results[i].Ticket, results[i].Err = svc.UpdateTicket(context, op.Ticket)
+		case ticket.ActionDelete:
+			results[i].Err = svc.DeleteTicket(context, op.Ticket.ID, op.Ticket.Version)
+		}
+	}
+
+	return results, nil
+}
+
+// batchAtomic applies the operations in one transaction, stopping at the first that fails.
+func (svc TicketService) batchAtomic(context context.Context, operations []TicketOperation) (results []TicketOperationResult, err error) {
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return nil, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	results = make([]TicketOperationResult, len(operations))
+	for i, op := range operations {
+		results[i].Ticket, results[i].Err = svc.applyOperation(context, tx, op)
+		if results[i].Err != nil {
+			return abortBatch(results, i), tx.Rollback()
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("could not commit tx: %w", err)
+	}
+
+	return results, nil
+}
+
+// applyOperation applies an operation using the given transaction.
+func (svc TicketService) applyOperation(context context.Context, tx repository.Tx, op TicketOperation) (ticket.TicketWithMetadata, error) {
+	switch op.Action {
+	case ticket.ActionCreate:
+		return svc.createTicket(context, tx, op.Ticket)
+	case ticket.ActionUpdate:
+		return svc.replaceTicket(context, tx, op.Ticket)
+	default:
+		return ticket.TicketWithMetadata{}, svc.deleteTicket(context, tx, op.Ticket.ID, op.Ticket.Version)
+	}
+}
+
+// abortBatch returns the results with every operation other than the failed operation returning
+// BatchAbortedError.
+func abortBatch(results []TicketOperationResult, failed int) []TicketOperationResult {
+	for i := range results {
+		if i != failed {
+			results[i] = TicketOperationResult{Err: BatchAbortedError{Message: fmt.Sprintf("not applied: operation %d failed", failed)}}
+		}
+	}
+	return results
+}
+
+// checkOperation returns an error if the operation has an unknown action or is missing the ticket id.
+func checkOperation(op TicketOperation) error {
+	switch op.Action {
+	case ticket.ActionCreate:
+		return nil
+	case ticket.ActionUpdate, ticket.ActionDelete:
+		if op.Ticket.ID == "" {
+			return fmt.Errorf("%s requires a ticket id", op.Action)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q", op.Action)
+	}
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	pkgrepository "github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
+)
+
+func TestShouldApplyAtomicBatch(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	existing, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	existing.Summary = "new summary"
+
+	// When
+	results, err := svc.BatchTickets(admin, []service.TicketOperation{
+		{Action: ticket.ActionCreate, Ticket: mockTicket("bob")},
+		{Action: ticket.ActionUpdate, Ticket: existing},
+	}, true)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.NotEmpty(t, results[0].Ticket.ID)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, "new summary", results[1].Ticket.Summary)
+	assert.Equal(t, uint64(2), countTickets(t, svc))
+}
+
+func TestShouldRollBackAtomicBatchOnFailure(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	existing, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	updated := existing
+	updated.Summary = "new summary"
+	closed := mockTicket("bob")
+	closed.Status = "closed"
+
+	// When
+	results, err := svc.BatchTickets(admin, []service.TicketOperation{
+		{Action: ticket.ActionCreate, Ticket: mockTicket("bob")},
+		{Action: ticket.ActionUpdate, Ticket: updated},
+		{Action: ticket.ActionCreate, Ticket: closed},
+		{Action: ticket.ActionDelete, Ticket: existing},
+	}, true)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.ErrorAs(t, results[0].Err, &service.BatchAbortedError{})
+	assert.ErrorAs(t, results[1].Err, &service.BatchAbortedError{})
+	assert.ErrorAs(t, results[2].Err, &workflow.TransitionError{})
+	assert.ErrorAs(t, results[3].Err, &service.BatchAbortedError{})
+	assert.Contains(t, results[0].Err.Error(), "operation 2 failed")
+	assert.Equal(t, uint64(1), countTickets(t, svc))
+	read, err := svc.ReadTicket(admin, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, existing.Summary, read.Summary)
+	assert.Equal(t, existing.Version, read.Version)
+}
+
+func TestShouldApplyIndependentBatchRegardlessOfFailures(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	existing, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	stale := existing
+	stale.Version = "stale"
+
+	// When
+	results, err := svc.BatchTickets(admin, []service.TicketOperation{
+		{Action: ticket.ActionCreate, Ticket: mockTicket("bob")},
+		{Action: ticket.ActionUpdate, Ticket: stale},
+		{Action: ticket.ActionDelete, Ticket: ticket.TicketWithMetadata{Metadata: ticket.Metadata{ID: "missing"}}},
+		{Action: ticket.ActionDelete, Ticket: existing},
+	}, false)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorAs(t, results[1].Err, &pkgrepository.ConflictError{})
+	assert.ErrorAs(t, results[2].Err, &pkgrepository.NotFoundError{})
+	assert.NoError(t, results[3].Err)
+	_, err = svc.ReadTicket(admin, results[0].Ticket.ID)
+	assert.NoError(t, err)
+	_, err = svc.ReadTicket(admin, existing.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldAuthorizeEachBatchOperation(t *testing.T) {
+	// Given
+	svc := newTicketService(t, authz.Policy{
+		Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}, "reporter": {"CreateTicket"}},
+	})
+	existing, err := svc.CreateTicket(as("admin", "admin"), mockTicket("alice"))
+	require.NoError(t, err)
+
+	// When
+	results, err := svc.BatchTickets(as("alice", "reporter"), []service.TicketOperation{
+		{Action: ticket.ActionCreate, Ticket: mockTicket("alice")},
+		{Action: ticket.ActionDelete, Ticket: existing},
+	}, false)
+
+	// Then
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorAs(t, results[1].Err, &authz.AuthorizationError{})
+}
+
+func TestShouldLimitBatchOperations(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	operations := make([]service.TicketOperation, service.MaxBatchOperations+1)
+	for i := range operations {
+		operations[i] = service.TicketOperation{Action: ticket.ActionCreate, Ticket: mockTicket("alice")}
+	}
+
+	// When
+	_, tooManyErr := svc.BatchTickets(admin, operations, true)
+	tooManyCount := countTickets(t, svc)
+	results, maxErr := svc.BatchTickets(admin, operations[:service.MaxBatchOperations], true)
+
+	// Then
+	assert.ErrorAs(t, tooManyErr, &service.RequestError{})
+	assert.Contains(t, tooManyErr.Error(), "1000")
+	assert.Equal(t, uint64(0), tooManyCount)
+	require.NoError(t, maxErr)
+	assert.Len(t, results, service.MaxBatchOperations)
+	assert.Equal(t, uint64(service.MaxBatchOperations), countTickets(t, svc))
+}
+
+func TestShouldRejectInvalidBatchOperation(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+
+	// When
+	_, unknownErr := svc.BatchTickets(admin, []service.TicketOperation{{Action: "archive", Ticket: mockTicket("alice")}}, false)
+	_, missingIDErr := svc.BatchTickets(admin, []service.TicketOperation{
+		{Action: ticket.ActionCreate, Ticket: mockTicket("alice")},
+		{Action: ticket.ActionUpdate, Ticket: mockTicket("alice")},
+	}, false)
+
+	// Then
+	assert.ErrorAs(t, unknownErr, &service.RequestError{})
+	assert.ErrorAs(t, missingIDErr, &service.RequestError{})
+	assert.Contains(t, missingIDErr.Error(), "operation 1")
+	assert.Equal(t, uint64(0), countTickets(t, svc))
+}
+
+// countTickets returns the number of tickets not in the trash.
+func countTickets(t *testing.T, svc service.TicketService) uint64 {
+	tickets, err := svc.QueryTickets(as("admin", "admin"), collection.QuerySpec{Count: true})
+	require.NoError(t, err)
+	return *tickets.Total
+}
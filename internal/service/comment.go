@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+var commentCapabilities = map[string]collection.FieldCapability{
+	"author":     {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true},
+	"body":       {Filter: true, FilterOps: cql.PatternOps},
+	"created_at": {Filter: true, FilterOps: cql.NumberOps, Sort: true, Type: collection.TypeTime},
+	"updated_at": {Filter: true, FilterOps: cql.NumberOps, Sort: true, Type: collection.TypeTime},
+	"created_by": {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true},
+	"updated_by": {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true},
+}
+
+var commentDefaultSorts = []collection.SortExpr{{Field: "created_at", Direction: cql.SortAsc}}
+
+type CommentService struct {
+	authorizer authz.ResourceAuthorizer
+	repository CommentRepository
+	tickets    TicketRepository
+}
+
+type CommentRepository repository.Repository[ticket.CommentWithMetadata]
+
+// NewCommentService creates a CommentService. Comments and tickets must be stored in repositories
+// that share transactions. Comments may only be accessed by principals authorized to read their ticket.
+func NewCommentService(r CommentRepository, t TicketRepository, a authz.ResourceAuthorizer) CommentService {
+	return CommentService{repository: r, tickets: t, authorizer: a}
+}
+
+func (svc CommentService) QueryComments(context context.Context, ticketID string, query collection.QuerySpec) (collection.Page[ticket.CommentWithMetadata], error) {
+	if err := svc.authorizer.IsAuthorized(context, "QueryComments"); err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, err
+	}
+
+	ApplyQueryDefaults(&query)
+	if err := query.Validate(commentCapabilities); err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, err
+	}
+	if len(query.Sorts) == 0 {
+		query.Sorts = commentDefaultSorts
+	}
+	query.Filters = append(query.Filters, collection.FilterExpr{Field: "ticket_id", Operator: cql.OpEq, Value: ticketID})
+
+	scope, err := scopeFilters(context, svc.authorizer, "QueryComments", commentCapabilities)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, err
+	}
+	query.Filters = append(query.Filters, scope...)
+
+	tx, err := svc.repository.StartTx(context, true)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	if err := svc.authorizeTicket(context, tx, ticketID); err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, err
+	}
+
+	comments, err := svc.repository.Query(tx, query)
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, fmt.Errorf("query comment from repository failed: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return collection.Page[ticket.CommentWithMetadata]{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return comments, nil
+}
+
+func (svc CommentService) ReadComment(context context.Context, ticketID string, commentID string) (ticket.CommentWithMetadata, error) {
+	if err := svc.authorizer.IsAuthorized(context, "ReadComment"); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	tx, err := svc.repository.StartTx(context, true)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	c, err := svc.readTicketComment(context, tx, ticketID, commentID)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "ReadComment", c, nil); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return c, nil
+}
+
+func (svc CommentService) CreateComment(context context.Context, c ticket.CommentWithMetadata) (ticket.CommentWithMetadata, error) {
+	if err := svc.authorizer.IsAuthorized(context, "CreateComment"); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	principal := authz.PrincipalFromContext(context)
+	if !principal.IsAnonymous() {
+		c.Author = principal.Subject
+	}
+	c.CreatedBy = principal.Subject
+	c.UpdatedBy = principal.Subject
+
+	if err := c.Comment.Validate(); err != nil {
+		return ticket.CommentWithMetadata{}, RequestError{Message: err.Error()}
+	}
+
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	if err := svc.authorizeTicket(context, tx, c.TicketID); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "CreateComment", nil, c); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	newComment, err := svc.repository.Create(tx, c)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("create comment in repository failed: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return newComment, nil
+}
+
+// UpdateComment edits the body of a comment. All other comment properties are preserved.
+func (svc CommentService) UpdateComment(context context.Context, c ticket.CommentWithMetadata) (ticket.CommentWithMetadata, error) {
+	if err := svc.authorizer.IsAuthorized(context, "UpdateComment"); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	currentComment, err := svc.readTicketComment(context, tx, c.TicketID, c.ID)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	c.Author = currentComment.Author
+	c.UpdatedBy = authz.PrincipalFromContext(context).Subject
+	if err := c.Validate(); err != nil {
+		return ticket.CommentWithMetadata{}, RequestError{Message: err.Error()}
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "UpdateComment", currentComment, c); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	updatedComment, err := svc.repository.Update(tx, c)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("update comment in repository failed: %w", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return updatedComment, nil
+}
+
+// DeleteComment deletes a comment. Unless version is "", returns ConflictError if the comment has been
+// modified since the version was read.
+func (svc CommentService) DeleteComment(context context.Context, ticketID string, commentID string, version string) error {
+	if err := svc.authorizer.IsAuthorized(context, "DeleteComment"); err != nil {
+		return err
+	}
+
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	currentComment, err := svc.readTicketComment(context, tx, ticketID, commentID)
+	if err != nil {
+		return err
+	}
+
+	if version != "" && version != currentComment.Version {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "DeleteComment", currentComment, nil); err != nil {
+		return err
+	}
+
+	err = svc.repository.Delete(tx, commentID, currentComment.Version)
+	if err != nil {
+		return fmt.Errorf("delete comment from repository: %w", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// authorizeTicket reads a ticket that is not in the trash and checks the context principal may read it.
+func (svc CommentService) authorizeTicket(context context.Context, tx repository.Tx, ticketID string) error {
+	t, err := svc.tickets.Read(tx, ticketID)
+	if err != nil {
+		return fmt.Errorf("read ticket from repository failed: %w", err)
+	}
+
+	return svc.authorizer.IsAuthorizedResource(context, "ReadTicket", t, nil)
+}
+
+// readTicketComment reads a comment and checks it belongs to the ticket, and that the ticket is not in
+// the trash and may be read by the context principal.
+func (svc CommentService) readTicketComment(context context.Context, tx repository.Tx, ticketID string, commentID string) (ticket.CommentWithMetadata, error) {
+	if err := svc.authorizeTicket(context, tx, ticketID); err != nil {
+		return ticket.CommentWithMetadata{}, err
+	}
+
+	c, err := svc.repository.Read(tx, commentID)
+	if err != nil {
+		return ticket.CommentWithMetadata{}, fmt.Errorf("read comment from repository failed: %w", err)
+	}
+
+	if c.TicketID != ticketID {
+		return ticket.CommentWithMetadata{}, NotFoundError{
+			Message: fmt.Sprintf("no comment with id %s found for ticket %s", commentID, ticketID),
+		}
+	}
+
+	return c, nil
+}
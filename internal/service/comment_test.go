@@ -0,0 +1,157 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	pkgrepository "github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
+)
+
+var commenterPolicy = authz.Policy{
+	Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}},
+	Rules: []authz.Rule{
+		{
+			Roles:      []string{"commenter"},
+			Operations: []authz.Operation{"ReadTicket"},
+			Before:     []string{"reporter==$subject"},
+		},
+		{
+			Roles:      []string{"commenter"},
+			Operations: []authz.Operation{"QueryComments", "ReadComment", "CreateComment"},
+		},
+		{
+			Roles:      []string{"commenter"},
+			Operations: []authz.Operation{"UpdateComment", "DeleteComment"},
+			Before:     []string{"author==$subject"},
+		},
+	},
+}
+
+func TestShouldNotQueryCommentsOfUnauthorizedTicket(t *testing.T) {
+	// Given
+	tickets, comments := newCommentServices(t, commenterPolicy)
+	created, err := tickets.CreateTicket(as("admin", "admin"), mockTicket("bob"))
+	require.NoError(t, err)
+
+	// When
+	_, err = comments.QueryComments(as("alice", "commenter"), created.ID, collection.QuerySpec{})
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+}
+
+func TestShouldNotCreateCommentOnUnauthorizedTicket(t *testing.T) {
+	// Given
+	tickets, comments := newCommentServices(t, commenterPolicy)
+	created, err := tickets.CreateTicket(as("admin", "admin"), mockTicket("bob"))
+	require.NoError(t, err)
+
+	// When
+	_, err = comments.CreateComment(as("alice", "commenter"), mockComment(created.ID))
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+}
+
+func TestShouldOnlyUpdateOwnComment(t *testing.T) {
+	// Given
+	tickets, comments := newCommentServices(t, commenterPolicy)
+	created, err := tickets.CreateTicket(as("admin", "admin"), mockTicket("alice"))
+	require.NoError(t, err)
+	own, err := comments.CreateComment(as("alice", "commenter"), mockComment(created.ID))
+	require.NoError(t, err)
+	other, err := comments.CreateComment(as("admin", "admin"), mockComment(created.ID))
+	require.NoError(t, err)
+
+	// When
+	_, ownErr := comments.UpdateComment(as("alice", "commenter"), own)
+	_, otherErr := comments.UpdateComment(as("alice", "commenter"), other)
+
+	// Then
+	assert.NoError(t, ownErr)
+	assert.ErrorAs(t, otherErr, &authz.AuthorizationError{})
+}
+
+func TestShouldOnlyDeleteOwnComment(t *testing.T) {
+	// Given
+	tickets, comments := newCommentServices(t, commenterPolicy)
+	created, err := tickets.CreateTicket(as("admin", "admin"), mockTicket("alice"))
+	require.NoError(t, err)
+	own, err := comments.CreateComment(as("alice", "commenter"), mockComment(created.ID))
+	require.NoError(t, err)
+	other, err := comments.CreateComment(as("admin", "admin"), mockComment(created.ID))
+	require.NoError(t, err)
+
+	// When
+	ownErr := comments.DeleteComment(as("alice", "commenter"), created.ID, own.ID, "")
+	otherErr := comments.DeleteComment(as("alice", "commenter"), created.ID, other.ID, "")
+
+	// Then
+	assert.NoError(t, ownErr)
+	assert.ErrorAs(t, otherErr, &authz.AuthorizationError{})
+}
+
+func TestShouldNotAccessCommentsOfTicketInTrash(t *testing.T) {
+	// Given
+	tickets, comments := newCommentServices(t, commenterPolicy)
+	admin := as("admin", "admin")
+	created, err := tickets.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	c, err := comments.CreateComment(admin, mockComment(created.ID))
+	require.NoError(t, err)
+	require.NoError(t, tickets.DeleteTicket(admin, created.ID, ""))
+
+	// When
+	_, readErr := comments.ReadComment(admin, created.ID, c.ID)
+	_, updateErr := comments.UpdateComment(admin, c)
+	deleteErr := comments.DeleteComment(admin, created.ID, c.ID, "")
+
+	// Then
+	assert.ErrorAs(t, readErr, &pkgrepository.NotFoundError{})
+	assert.ErrorAs(t, updateErr, &pkgrepository.NotFoundError{})
+	assert.ErrorAs(t, deleteErr, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldAccessCommentsOfRestoredTicket(t *testing.T) {
+	// Given
+	tickets, comments := newCommentServices(t, commenterPolicy)
+	admin := as("admin", "admin")
+	created, err := tickets.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	c, err := comments.CreateComment(admin, mockComment(created.ID))
+	require.NoError(t, err)
+	require.NoError(t, tickets.DeleteTicket(admin, created.ID, ""))
+	_, err = tickets.RestoreTicket(admin, created.ID)
+	require.NoError(t, err)
+
+	// When
+	read, err := comments.ReadComment(admin, created.ID, c.ID)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, c.ID, read.ID)
+}
+
+// newCommentServices creates a TicketService and CommentService sharing an in-memory store and
+// enforcing the policy.
+func newCommentServices(t *testing.T, policy authz.Policy) (service.TicketService, service.CommentService) {
+	store := repository.NewMemoryStore()
+	authorizer, err := authz.NewRoleAuthorizer(policy, service.AuthorizedResources()...)
+	require.NoError(t, err)
+	tickets := repository.NewMemoryTicketRepository(store)
+
+	return service.NewTicketService(tickets, repository.NewMemoryHistoryRepository(), authorizer, workflow.Default),
+		service.NewCommentService(repository.NewMemoryCommentRepository(store), tickets, authorizer)
+}
+
+func mockComment(ticketID string) ticket.CommentWithMetadata {
+	return ticket.CommentWithMetadata{Comment: ticket.Comment{TicketID: ticketID, Body: "mock body"}}
+}
@@ -10,3 +10,36 @@ type RequestError struct {
 func (ve RequestError) Error() string {
 	return ve.Message
 }
+
+/*
+ * The requested resource does not exist.
+ */
+type NotFoundError struct {
+	Message string
+}
+
+func (nfe NotFoundError) Error() string {
+	return nfe.Message
+}
+
+/*
+ * The request is well formed but its result is not valid.
+ */
+type UnprocessableError struct {
+	Message string
+}
+
+func (ue UnprocessableError) Error() string {
+	return ue.Message
+}
+
+/*
+ * The operation was not applied because another operation in the same batch failed.
+ */
+type BatchAbortedError struct {
+	Message string
+}
+
+func (bae BatchAbortedError) Error() string {
+	return bae.Message
+}
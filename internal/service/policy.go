@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+// Operations authorized on each kind of resource. The conditions of policy rules granting them are
+// matched against resources of that kind.
+var (
+	ticketOperations  = []authz.Operation{"QueryTickets", "ReadTicket", "CreateTicket", "UpdateTicket", "DeleteTicket", "QueryTicketHistory"}
+	trashOperations   = []authz.Operation{"QueryTrash", "RestoreTicket", "PurgeTickets"}
+	commentOperations = []authz.Operation{"QueryComments", "ReadComment", "CreateComment", "UpdateComment", "DeleteComment"}
+)
+
+// AuthorizedResources describes the resources authorized by the services, so that the conditions of
+// policy rules are checked against the fields of the resources of the operations they grant when the
+// authorizer is created, rather than when queried. Rules granting every operation must be valid for
+// every kind of resource.
+func AuthorizedResources() []authz.Resource {
+	return []authz.Resource{
+		{Operations: ticketOperations, Fields: ticketCapabilities},
+		{Operations: trashOperations, Fields: trashCapabilities},
+		{Operations: commentOperations, Fields: commentCapabilities},
+	}
+}
+
+// scopeFilters returns the filters restricting a query operation to the resources the context
+// principal may see, with their values converted to the types of their fields as query filters are.
+func scopeFilters(context context.Context, a authz.ResourceAuthorizer, operation authz.Operation, fieldCapabilities map[string]collection.FieldCapability) ([]collection.Expr, error) {
+	filters, err := a.Scope(context, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	validated, err := collection.ValidateFilters(filters, fieldCapabilities)
+	if err != nil {
+		// not a caller error: the policy should have been checked against AuthorizedResources
+		return nil, fmt.Errorf("invalid scope of %s: %s", operation, err)
+	}
+
+	return validated, nil
+}
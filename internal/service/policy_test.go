@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+func TestShouldAcceptPolicyWithValidConditions(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(commenterPolicy, service.AuthorizedResources()...)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldRejectPolicyWithUnknownField(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"reporter"}, Operations: []authz.Operation{"QueryTickets"}, Before: []string{"repoter==$subject"}},
+	}}, service.AuthorizedResources()...)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+}
+
+func TestShouldAcceptPolicyWithStatusNotInWorkflow(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"agent"}, Operations: []authz.Operation{"QueryTrash"}, Before: []string{"status==done"}},
+	}}, service.AuthorizedResources()...)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldScopeQueryByTypedConditions(t *testing.T) {
+	// Given
+	svc := newTicketService(t, authz.Policy{
+		Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}},
+		Rules: []authz.Rule{{
+			Roles:      []string{"triager"},
+			Operations: []authz.Operation{"QueryTickets"},
+			Before:     []string{"priority>=high", "created_at>now-1h"},
+		}},
+	})
+	low := mockTicket("bob")
+	low.Priority = "low"
+	high := mockTicket("bob")
+	high.Priority = "high"
+	_, err := svc.CreateTicket(as("admin", "admin"), low)
+	require.NoError(t, err)
+	created, err := svc.CreateTicket(as("admin", "admin"), high)
+	require.NoError(t, err)
+
+	// When
+	tickets, err := svc.QueryTickets(as("alice", "triager"), collection.QuerySpec{})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, tickets.Results, 1)
+	assert.Equal(t, created.ID, tickets.Results[0].ID)
+}
+
+func TestShouldAuthorizeUpdateByTypedConditions(t *testing.T) {
+	// Given
+	svc := newTicketService(t, authz.Policy{
+		Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}},
+		Rules: []authz.Rule{{
+			Roles:      []string{"triager"},
+			Operations: []authz.Operation{"UpdateTicket"},
+			Before:     []string{"priority>=high", "created_at>now-1h"},
+		}},
+	})
+	low := mockTicket("bob")
+	low.Priority = "low"
+	critical := mockTicket("bob")
+	critical.Priority = "critical"
+	lowCreated, err := svc.CreateTicket(as("admin", "admin"), low)
+	require.NoError(t, err)
+	criticalCreated, err := svc.CreateTicket(as("admin", "admin"), critical)
+	require.NoError(t, err)
+
+	// When
+	_, lowErr := svc.UpdateTicket(as("alice", "triager"), lowCreated)
+	_, criticalErr := svc.UpdateTicket(as("alice", "triager"), criticalCreated)
+
+	// Then
+	assert.ErrorAs(t, lowErr, &authz.AuthorizationError{})
+	assert.NoError(t, criticalErr)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+)
+
+// sweeperPrincipal is the principal recorded in the history of tickets purged by a TrashSweeper.
+var sweeperPrincipal = authz.Principal{Subject: "trash-sweeper", Roles: []string{}}
+
+// TrashSweeper periodically purges tickets that have been in the trash for longer than the
+// retention period.
+type TrashSweeper struct {
+	service   TicketService
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+	started   chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewTrashSweeper creates a TrashSweeper that, every interval, purges the tickets moved to the trash
+// more than retention ago. The sweeper is not subject to the authorization policy: it purges using
+// a copy of the service that authorizes every operation.
+func NewTrashSweeper(svc TicketService, retention time.Duration, interval time.Duration) TrashSweeper {
+	svc.authorizer = authz.AlwaysAuthorize{}
+	return TrashSweeper{
+		service:   svc,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+		started:   make(chan struct{}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// WithClock returns a copy of the sweeper that measures the time tickets have been in the trash from
+// the times returned by now instead of the current time.
+func (s TrashSweeper) WithClock(now func() time.Time) TrashSweeper {
+	s.now = now
+	return s
+}
+
+// Start sweeps the trash now and then every interval until stopped.
+func (s TrashSweeper) Start() {
+	close(s.started)
+	go func() {
+		defer close(s.done)
+		log.Println("Trash sweeper started, retention", s.retention)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			s.sweep()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sweeping, waiting for a sweep in progress to finish. Stop returns immediately if the
+// sweeper was not started.
+func (s TrashSweeper) Stop() {
+	log.Println("Stopping trash sweeper")
+	close(s.stop)
+	select {
+	case <-s.started:
+		<-s.done
+	default:
+	}
+	log.Println("Trash sweeper stopped")
+}
+
+// sweep purges the tickets moved to the trash more than retention ago.
+func (s TrashSweeper) sweep() {
+	ctx := authz.WithPrincipal(context.Background(), sweeperPrincipal)
+	purged, err := s.service.PurgeTrash(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		log.Println("Trash sweep failed:", err)
+		return
+	}
+	if len(purged) > 0 {
+		log.Println("Trash sweep purged", len(purged), "tickets")
+	}
+}
@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+func TestShouldSweepOnlyTicketsPastRetention(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	live, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	trashed, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTicket(admin, trashed.ID, ""))
+	trash, err := svc.QueryTrashedTickets(admin, collection.QuerySpec{})
+	require.NoError(t, err)
+	require.Len(t, trash.Results, 1)
+	deletedAt := *trash.Results[0].DeletedAt
+	retention := 24 * time.Hour
+
+	// When
+	sweep(svc, retention, deletedAt.Add(retention-time.Minute))
+	withinRetention, err := svc.QueryTrashedTickets(admin, collection.QuerySpec{})
+	require.NoError(t, err)
+	sweep(svc, retention, deletedAt.Add(retention+time.Minute))
+	pastRetention, err := svc.QueryTrashedTickets(admin, collection.QuerySpec{})
+	require.NoError(t, err)
+
+	// Then
+	assert.Len(t, withinRetention.Results, 1)
+	assert.Empty(t, pastRetention.Results)
+	_, err = svc.ReadTicket(admin, live.ID)
+	assert.NoError(t, err)
+	history, err := svc.QueryTicketHistory(admin, trashed.ID, collection.QuerySpec{})
+	require.NoError(t, err)
+	assert.Len(t, history.Results, 3)
+}
+
+func TestShouldSweepTrashRegardlessOfPolicy(t *testing.T) {
+	// Given
+	svc := newTicketService(t, reporterPolicy)
+	admin := as("admin", "admin")
+	trashed, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTicket(admin, trashed.ID, ""))
+
+	// When
+	sweep(svc, 0, time.Now().Add(time.Minute))
+
+	// Then
+	trash, err := svc.QueryTrashedTickets(admin, collection.QuerySpec{})
+	require.NoError(t, err)
+	assert.Empty(t, trash.Results)
+}
+
+func TestShouldStopSweeperThatWasNotStarted(t *testing.T) {
+	// Given
+	sweeper := service.NewTrashSweeper(newTicketService(t, adminPolicy), time.Hour, time.Hour)
+	stopped := make(chan struct{})
+
+	// When
+	go func() {
+		sweeper.Stop()
+		close(stopped)
+	}()
+
+	// Then
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+// sweep sweeps the trash once at the given time.
+func sweep(svc service.TicketService, retention time.Duration, now time.Time) {
+	sweeper := service.NewTrashSweeper(svc, retention, time.Hour).WithClock(func() time.Time { return now })
+	sweeper.Start()
+	sweeper.Stop()
+}
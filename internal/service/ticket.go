@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"golang.org/x/exp/maps"
 
 	"github.com/grantjforrester/go-ticket/pkg/authz"
 	"github.com/grantjforrester/go-ticket/pkg/collection"
 	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
 	"github.com/grantjforrester/go-ticket/pkg/repository"
 	"github.com/grantjforrester/go-ticket/pkg/ticket"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
 )
 
 var QueryDefaults = struct {
@@ -20,19 +24,90 @@ var QueryDefaults = struct {
 	Size: uint64(100),
 }
 
+// ticketCapabilities are the capabilities of ticket fields. Status filters may use any status, as
+// tickets created before the workflow was configured may be in statuses that are not workflow states.
+// Priorities are compared by their order, lowest first.
 var ticketCapabilities = map[string]collection.FieldCapability{
-	"summary": {Filter: true, FilterOps: cql.StringOps, Sort: true},
+	"id":          {Select: true},
+	"version":     {Select: true},
+	"summary":     {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps, cql.PatternOps), Sort: true, Select: true, Search: true},
+	"description": {Filter: true, FilterOps: cql.PatternOps, Select: true, Search: true},
+	"status":      {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true, Select: true, Group: true},
+	"priority":    {Filter: true, FilterOps: cql.Ops(cql.NumberOps, cql.SetOps), Sort: true, Select: true, Group: true, Type: collection.TypeEnum, Values: ticket.Priorities},
+	"severity":    {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps, cql.NullOps), Sort: true, Select: true, Group: true, Type: collection.TypeEnum, Values: ticket.Severities},
+	"assignee":    {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps, cql.NullOps), Sort: true, Select: true, Group: true},
+	"reporter":    {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true, Select: true, Group: true},
+	"created_at":  {Filter: true, FilterOps: cql.NumberOps, Sort: true, Select: true, Type: collection.TypeTime},
+	"updated_at":  {Filter: true, FilterOps: cql.NumberOps, Sort: true, Select: true, Type: collection.TypeTime},
+	"created_by":  {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true, Select: true, Group: true},
+	"updated_by":  {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true, Select: true, Group: true},
+}
+
+// trashCapabilities are the capabilities of the fields of tickets in the trash.
+var trashCapabilities = func() map[string]collection.FieldCapability {
+	capabilities := maps.Clone(ticketCapabilities)
+	capabilities["deleted_at"] = collection.FieldCapability{Filter: true, FilterOps: cql.NumberOps, Sort: true, Select: true, Type: collection.TypeTime}
+	capabilities["deleted_by"] = collection.FieldCapability{Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true, Select: true}
+	return capabilities
+}()
+
+var historyCapabilities = map[string]collection.FieldCapability{
+	"action":     {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Type: collection.TypeEnum, Values: []string{string(ticket.ActionCreate), string(ticket.ActionUpdate), string(ticket.ActionDelete), string(ticket.ActionRestore), string(ticket.ActionPurge)}},
+	"changed_by": {Filter: true, FilterOps: cql.Ops(cql.StringOps, cql.SetOps), Sort: true},
+	"changed_at": {Filter: true, FilterOps: cql.NumberOps, Sort: true, Type: collection.TypeTime},
 }
 
+var historyDefaultSorts = []collection.SortExpr{{Field: "changed_at", Direction: cql.SortAsc}}
+
 type TicketService struct {
-	authorizer authz.Authorizer
+	authorizer authz.ResourceAuthorizer
 	repository TicketRepository
+	history    HistoryRepository
+	workflow   workflow.Workflow
+}
+
+// TicketRepository stores tickets.
+type TicketRepository interface {
+	repository.Repository[ticket.TicketWithMetadata]
+
+	// Facets counts the tickets matching the filters of the facet query with each value of each of
+	// its fields using the given transaction.
+	Facets(repository.Tx, collection.FacetSpec) (collection.Facets, error)
+
+	// Trash moves the ticket to the trash using the given transaction. Tickets in the trash are not
+	// found by Read, Update, Query or Facets. Unless version is "", returns ConflictError if the
+	// ticket has been modified since the version was read.
+	Trash(tx repository.Tx, ticketID string, version string, deletedBy string) error
+
+	// ReadTrash finds a ticket in the trash using the given transaction.
+	ReadTrash(repository.Tx, string) (ticket.TicketWithMetadata, error)
+
+	// QueryTrash finds tickets in the trash based on the criteria in the query using the given
+	// transaction.
+	QueryTrash(repository.Tx, repository.Query) (collection.Page[ticket.TicketWithMetadata], error)
+
+	// Restore moves the ticket out of the trash using the given transaction.
+	Restore(tx repository.Tx, ticketID string, restoredBy string) (ticket.TicketWithMetadata, error)
+
+	// Purge permanently deletes the tickets moved to the trash before the given time using the given
+	// transaction, returning their ids.
+	Purge(repository.Tx, time.Time) ([]string, error)
 }
 
-type TicketRepository repository.Repository[ticket.TicketWithMetadata]
+// HistoryRepository is an append-only store of ticket changes.
+type HistoryRepository interface {
+
+	// Append records the history entry using the given transaction.
+	Append(repository.Tx, ticket.HistoryEntry) error
+
+	// Query finds history entries based on the criteria in the query using the given transaction.
+	Query(repository.Tx, repository.Query) (collection.Page[ticket.HistoryEntry], error)
+}
 
-func NewTicketService(r TicketRepository, a authz.Authorizer) TicketService {
-	return TicketService{repository: r, authorizer: a}
+// NewTicketService creates a TicketService. Tickets and their history must be stored in repositories
+// that share transactions.
+func NewTicketService(r TicketRepository, h HistoryRepository, a authz.ResourceAuthorizer, w workflow.Workflow) TicketService {
+	return TicketService{repository: r, history: h, authorizer: a, workflow: w}
 }
 
 func (svc TicketService) QueryTickets(context context.Context, query collection.QuerySpec) (collection.Page[ticket.TicketWithMetadata], error) {
@@ -40,11 +115,17 @@ func (svc TicketService) QueryTickets(context context.Context, query collection.
 		return collection.Page[ticket.TicketWithMetadata]{}, err
 	}
 
-	applyDefaults(&query)
+	ApplyQueryDefaults(&query)
 	if err := query.Validate(ticketCapabilities); err != nil {
 		return collection.Page[ticket.TicketWithMetadata]{}, err
 	}
 
+	scope, err := scopeFilters(context, svc.authorizer, "QueryTickets", ticketCapabilities)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, err
+	}
+	query.Filters = append(query.Filters, scope...)
+
 	tx, err := svc.repository.StartTx(context, true)
 	if err != nil {
 		return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("could not start tx: %w", err)
@@ -84,6 +165,10 @@ func (svc TicketService) ReadTicket(context context.Context, ticketID string) (t
 		return ticket.TicketWithMetadata{}, fmt.Errorf("read ticket from repository failed: %w", err)
 	}
 
+	if err := svc.authorizer.IsAuthorizedResource(context, "ReadTicket", t, nil); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return ticket.TicketWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
@@ -93,14 +178,74 @@ func (svc TicketService) ReadTicket(context context.Context, ticketID string) (t
 }
 
 func (svc TicketService) CreateTicket(context context.Context, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	newTicket, err := svc.createTicket(context, tx, t)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return newTicket, nil
+}
+
+// createTicket creates a ticket using the given transaction, and records the change in its history.
+func (svc TicketService) createTicket(context context.Context, tx repository.Tx, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
 	if err := svc.authorizer.IsAuthorized(context, "CreateTicket"); err != nil {
 		return ticket.TicketWithMetadata{}, err
 	}
 
+	if t.Status == "" {
+		t.Status = svc.workflow.Initial
+	}
+
+	if t.Priority == "" {
+		t.Priority = ticket.DefaultPriority
+	}
+
+	subject := authz.PrincipalFromContext(context).Subject
+	if t.Reporter == "" {
+		t.Reporter = subject
+	}
+	t.CreatedBy = subject
+	t.UpdatedBy = subject
+
 	if err := t.Ticket.Validate(); err != nil {
 		return ticket.TicketWithMetadata{}, RequestError{Message: err.Error()}
 	}
 
+	if err := svc.workflow.CheckInitial(t.Status); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "CreateTicket", nil, t); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	newTicket, err := svc.repository.Create(tx, t)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("create ticket in repository failed: %w", err)
+	}
+
+	err = svc.appendHistory(context, tx, newTicket.ID, ticket.ActionCreate, ticket.Ticket{}, newTicket.Ticket)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	return newTicket, nil
+}
+
+func (svc TicketService) UpdateTicket(context context.Context, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
 	tx, err := svc.repository.StartTx(context, false)
 	if err != nil {
 		return ticket.TicketWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
@@ -109,9 +254,9 @@ func (svc TicketService) CreateTicket(context context.Context, t ticket.TicketWi
 		err = errors.Join(err, tx.Rollback())
 	}()
 
-	newTicket, err := svc.repository.Create(tx, t)
+	updatedTicket, err := svc.replaceTicket(context, tx, t)
 	if err != nil {
-		return ticket.TicketWithMetadata{}, fmt.Errorf("create ticket in repository failed: %w", err)
+		return ticket.TicketWithMetadata{}, err
 	}
 
 	err = tx.Commit()
@@ -119,10 +264,12 @@ func (svc TicketService) CreateTicket(context context.Context, t ticket.TicketWi
 		return ticket.TicketWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
 	}
 
-	return newTicket, nil
+	return updatedTicket, nil
 }
 
-func (svc TicketService) UpdateTicket(context context.Context, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
+// replaceTicket replaces the current version of a ticket using the given transaction, and records
+// the change in its history.
+func (svc TicketService) replaceTicket(context context.Context, tx repository.Tx, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
 	if err := svc.authorizer.IsAuthorized(context, "UpdateTicket"); err != nil {
 		return ticket.TicketWithMetadata{}, err
 	}
@@ -131,6 +278,26 @@ func (svc TicketService) UpdateTicket(context context.Context, t ticket.TicketWi
 		return ticket.TicketWithMetadata{}, RequestError{Message: err.Error()}
 	}
 
+	currentTicket, err := svc.repository.Read(tx, t.ID)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("read ticket from repository failed: %w", err)
+	}
+
+	return svc.updateTicket(context, tx, currentTicket, t)
+}
+
+// TicketPatch returns a ticket with changes to its properties.
+type TicketPatch func(ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error)
+
+// PatchTicket applies the patch to the current version of a ticket and updates the ticket with the
+// result. Unless version is "", returns ConflictError if the ticket has been modified since the
+// version was read. Returns UnprocessableError if the patched ticket is not valid. The metadata of
+// the ticket cannot be patched.
+func (svc TicketService) PatchTicket(context context.Context, ticketID string, version string, patch TicketPatch) (ticket.TicketWithMetadata, error) {
+	if err := svc.authorizer.IsAuthorized(context, "UpdateTicket"); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
 	tx, err := svc.repository.StartTx(context, false)
 	if err != nil {
 		return ticket.TicketWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
@@ -139,10 +306,30 @@ func (svc TicketService) UpdateTicket(context context.Context, t ticket.TicketWi
 		err = errors.Join(err, tx.Rollback())
 	}()
 
-	updatedTicket, err := svc.repository.Update(tx, t)
+	currentTicket, err := svc.repository.Read(tx, ticketID)
 	if err != nil {
-		return ticket.TicketWithMetadata{}, fmt.Errorf("update ticket in repository failed: %w", err)
+		return ticket.TicketWithMetadata{}, fmt.Errorf("read ticket from repository failed: %w", err)
+	}
+
+	if version != "" && version != currentTicket.Version {
+		return ticket.TicketWithMetadata{}, repository.ConflictError{Message: "version conflict"}
+	}
+
+	t, err := patch(currentTicket)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("patch ticket failed: %w", err)
+	}
+	t.Metadata = currentTicket.Metadata
+
+	if err := t.Validate(); err != nil {
+		return ticket.TicketWithMetadata{}, UnprocessableError{Message: err.Error()}
 	}
+
+	updatedTicket, err := svc.updateTicket(context, tx, currentTicket, t)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return ticket.TicketWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
@@ -151,11 +338,179 @@ func (svc TicketService) UpdateTicket(context context.Context, t ticket.TicketWi
 	return updatedTicket, nil
 }
 
-func (svc TicketService) DeleteTicket(context context.Context, ticketID string) error {
+// updateTicket replaces the current version of a ticket with a valid ticket using the given
+// transaction, and records the change in its history.
+func (svc TicketService) updateTicket(context context.Context, tx repository.Tx, currentTicket ticket.TicketWithMetadata, t ticket.TicketWithMetadata) (ticket.TicketWithMetadata, error) {
+	if t.Priority == "" {
+		t.Priority = currentTicket.Priority
+	}
+
+	if t.Reporter == "" {
+		t.Reporter = currentTicket.Reporter
+	}
+
+	t.CreatedAt = currentTicket.CreatedAt
+	t.CreatedBy = currentTicket.CreatedBy
+	t.UpdatedBy = authz.PrincipalFromContext(context).Subject
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "UpdateTicket", currentTicket, t); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	if err := svc.workflow.CheckTransition(currentTicket.Status, t.Status); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	updatedTicket, err := svc.repository.Update(tx, t)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("update ticket in repository failed: %w", err)
+	}
+
+	err = svc.appendHistory(context, tx, updatedTicket.ID, ticket.ActionUpdate, currentTicket.Ticket, updatedTicket.Ticket)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	return updatedTicket, nil
+}
+
+// DeleteTicket moves a ticket to the trash. Unless version is "", returns ConflictError if the ticket has been
+// modified since the version was read.
+func (svc TicketService) DeleteTicket(context context.Context, ticketID string, version string) error {
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	err = svc.deleteTicket(context, tx, ticketID, version)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// deleteTicket moves a ticket to the trash using the given transaction, and records the change in
+// its history.
+func (svc TicketService) deleteTicket(context context.Context, tx repository.Tx, ticketID string, version string) error {
 	if err := svc.authorizer.IsAuthorized(context, "DeleteTicket"); err != nil {
 		return err
 	}
 
+	currentTicket, err := svc.repository.Read(tx, ticketID)
+	if err != nil {
+		return fmt.Errorf("read ticket from repository failed: %w", err)
+	}
+
+	if version != "" && version != currentTicket.Version {
+		return repository.ConflictError{Message: "version conflict"}
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "DeleteTicket", currentTicket, nil); err != nil {
+		return err
+	}
+
+	err = svc.repository.Trash(tx, ticketID, currentTicket.Version, authz.PrincipalFromContext(context).Subject)
+	if err != nil {
+		return fmt.Errorf("trash ticket in repository: %w", err)
+	}
+
+	return svc.appendHistory(context, tx, ticketID, ticket.ActionDelete, currentTicket.Ticket, ticket.Ticket{})
+}
+
+// QueryTrashedTickets returns the tickets in the trash matching the query.
+func (svc TicketService) QueryTrashedTickets(context context.Context, query collection.QuerySpec) (collection.Page[ticket.TicketWithMetadata], error) {
+	if err := svc.authorizer.IsAuthorized(context, "QueryTrash"); err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, err
+	}
+
+	ApplyQueryDefaults(&query)
+	if err := query.Validate(trashCapabilities); err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, err
+	}
+
+	scope, err := scopeFilters(context, svc.authorizer, "QueryTrash", trashCapabilities)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, err
+	}
+	query.Filters = append(query.Filters, scope...)
+
+	tx, err := svc.repository.StartTx(context, true)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	tickets, err := svc.repository.QueryTrash(tx, query)
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("query trash from repository failed: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return collection.Page[ticket.TicketWithMetadata]{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return tickets, nil
+}
+
+// RestoreTicket moves a ticket out of the trash.
+func (svc TicketService) RestoreTicket(context context.Context, ticketID string) (ticket.TicketWithMetadata, error) {
+	if err := svc.authorizer.IsAuthorized(context, "RestoreTicket"); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	trashedTicket, err := svc.repository.ReadTrash(tx, ticketID)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("read trash from repository failed: %w", err)
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "RestoreTicket", trashedTicket, nil); err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	restoredTicket, err := svc.repository.Restore(tx, ticketID, authz.PrincipalFromContext(context).Subject)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("restore ticket in repository failed: %w", err)
+	}
+
+	err = svc.appendHistory(context, tx, ticketID, ticket.ActionRestore, ticket.Ticket{}, restoredTicket.Ticket)
+	if err != nil {
+		return ticket.TicketWithMetadata{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ticket.TicketWithMetadata{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return restoredTicket, nil
+}
+
+// PurgeTicket permanently deletes a ticket in the trash and its comments.
+func (svc TicketService) PurgeTicket(context context.Context, ticketID string) error {
+	if err := svc.authorizer.IsAuthorized(context, "PurgeTickets"); err != nil {
+		return err
+	}
+
 	tx, err := svc.repository.StartTx(context, false)
 	if err != nil {
 		return fmt.Errorf("could not start tx: %w", err)
@@ -164,10 +519,25 @@ func (svc TicketService) DeleteTicket(context context.Context, ticketID string)
 		err = errors.Join(err, tx.Rollback())
 	}()
 
-	err = svc.repository.Delete(tx, ticketID)
+	trashedTicket, err := svc.repository.ReadTrash(tx, ticketID)
+	if err != nil {
+		return fmt.Errorf("read trash from repository failed: %w", err)
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "PurgeTickets", trashedTicket, nil); err != nil {
+		return err
+	}
+
+	err = svc.repository.Delete(tx, ticketID, trashedTicket.Version)
 	if err != nil {
 		return fmt.Errorf("delete ticket from repository: %w", err)
 	}
+
+	err = svc.appendHistory(context, tx, ticketID, ticket.ActionPurge, ticket.Ticket{}, ticket.Ticket{})
+	if err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("cound not commit tx: %w", err)
@@ -176,8 +546,163 @@ func (svc TicketService) DeleteTicket(context context.Context, ticketID string)
 	return nil
 }
 
-func applyDefaults(query *collection.QuerySpec) {
-	if query.Page == 0 {
+// PurgeTrash permanently deletes the tickets moved to the trash before the given time and their
+// comments, returning the ids of the purged tickets.
+func (svc TicketService) PurgeTrash(context context.Context, before time.Time) ([]string, error) {
+	if err := svc.authorizer.IsAuthorized(context, "PurgeTickets"); err != nil {
+		return nil, err
+	}
+
+	tx, err := svc.repository.StartTx(context, false)
+	if err != nil {
+		return nil, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	purged, err := svc.repository.Purge(tx, before)
+	if err != nil {
+		return nil, fmt.Errorf("purge trash in repository failed: %w", err)
+	}
+
+	for _, ticketID := range purged {
+		err = svc.appendHistory(context, tx, ticketID, ticket.ActionPurge, ticket.Ticket{}, ticket.Ticket{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return purged, nil
+}
+
+// QueryTicketFacets returns the number of tickets matching the query with each value of each of
+// its fields.
+func (svc TicketService) QueryTicketFacets(context context.Context, query collection.FacetSpec) (collection.Facets, error) {
+	if err := svc.authorizer.IsAuthorized(context, "QueryTickets"); err != nil {
+		return collection.Facets{}, err
+	}
+
+	if err := query.Validate(ticketCapabilities); err != nil {
+		return collection.Facets{}, err
+	}
+
+	scope, err := scopeFilters(context, svc.authorizer, "QueryTickets", ticketCapabilities)
+	if err != nil {
+		return collection.Facets{}, err
+	}
+	query.Filters = append(query.Filters, scope...)
+
+	tx, err := svc.repository.StartTx(context, true)
+	if err != nil {
+		return collection.Facets{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	facets, err := svc.repository.Facets(tx, query)
+	if err != nil {
+		return collection.Facets{}, fmt.Errorf("query ticket facets from repository failed: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return collection.Facets{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return facets, nil
+}
+
+// QueryTicketHistory returns the changes made to a ticket, including a deleted ticket. The context
+// principal must be authorized to read the ticket.
+func (svc TicketService) QueryTicketHistory(context context.Context, ticketID string, query collection.QuerySpec) (collection.Page[ticket.HistoryEntry], error) {
+	if err := svc.authorizer.IsAuthorized(context, "QueryTicketHistory"); err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, err
+	}
+
+	ApplyQueryDefaults(&query)
+	if err := query.Validate(historyCapabilities); err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, err
+	}
+	if len(query.Sorts) == 0 {
+		query.Sorts = historyDefaultSorts
+	}
+	query.Filters = append(query.Filters, collection.FilterExpr{Field: "ticket_id", Operator: cql.OpEq, Value: ticketID})
+
+	tx, err := svc.repository.StartTx(context, true)
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, tx.Rollback())
+	}()
+
+	if err := svc.authorizeHistory(context, tx, ticketID); err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, err
+	}
+
+	entries, err := svc.history.Query(tx, query)
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, fmt.Errorf("query history from repository failed: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return collection.Page[ticket.HistoryEntry]{}, fmt.Errorf("cound not commit tx: %w", err)
+	}
+
+	return entries, nil
+}
+
+// authorizeHistory checks the context principal may read the history of a ticket, live or in the
+// trash, using the given transaction. The history of a purged ticket may only be read by principals
+// that may read every ticket.
+func (svc TicketService) authorizeHistory(context context.Context, tx repository.Tx, ticketID string) error {
+	var resource any
+	t, err := svc.repository.Read(tx, ticketID)
+	if errors.As(err, &repository.NotFoundError{}) {
+		t, err = svc.repository.ReadTrash(tx, ticketID)
+	}
+	switch {
+	case err == nil:
+		resource = t
+	case errors.As(err, &repository.NotFoundError{}):
+		resource = nil
+	default:
+		return fmt.Errorf("read ticket from repository failed: %w", err)
+	}
+
+	if err := svc.authorizer.IsAuthorizedResource(context, "QueryTicketHistory", resource, nil); err != nil {
+		return err
+	}
+	return svc.authorizer.IsAuthorizedResource(context, "ReadTicket", resource, nil)
+}
+
+// appendHistory records the change to a ticket by the context principal.
+func (svc TicketService) appendHistory(context context.Context, tx repository.Tx, ticketID string, action ticket.Action, before ticket.Ticket, after ticket.Ticket) error {
+	err := svc.history.Append(tx, ticket.HistoryEntry{
+		TicketID:  ticketID,
+		Action:    action,
+		ChangedBy: authz.PrincipalFromContext(context).Subject,
+		Changes:   ticket.Diff(before, after),
+	})
+	if err != nil {
+		return fmt.Errorf("append ticket history in repository failed: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyQueryDefaults sets the page and size of the query to QueryDefaults where they are not set.
+// The page is not set for a query after a cursor.
+func ApplyQueryDefaults(query *collection.QuerySpec) {
+	if query.Page == 0 && query.After == nil {
 		query.Page = QueryDefaults.Page
 	}
 
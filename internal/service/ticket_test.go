@@ -0,0 +1,204 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/internal/adapter/repository"
+	"github.com/grantjforrester/go-ticket/internal/service"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	pkgrepository "github.com/grantjforrester/go-ticket/pkg/repository"
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
+)
+
+var adminPolicy = authz.Policy{Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}}}
+
+var reporterPolicy = authz.Policy{
+	Roles: map[string][]authz.Operation{"admin": {authz.AnyOperation}},
+	Rules: []authz.Rule{{
+		Roles:      []string{"reporter"},
+		Operations: []authz.Operation{"QueryTickets", "ReadTicket", "QueryTicketHistory"},
+		Before:     []string{"reporter==$subject"},
+	}},
+}
+
+func TestShouldQueryHistoryOfAuthorizedTicket(t *testing.T) {
+	// Given
+	svc := newTicketService(t, reporterPolicy)
+	created, err := svc.CreateTicket(as("admin", "admin"), mockTicket("alice"))
+	require.NoError(t, err)
+
+	// When
+	history, err := svc.QueryTicketHistory(as("alice", "reporter"), created.ID, collection.QuerySpec{})
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, history.Results, 1)
+}
+
+func TestShouldNotQueryHistoryOfUnauthorizedTicket(t *testing.T) {
+	// Given
+	svc := newTicketService(t, reporterPolicy)
+	created, err := svc.CreateTicket(as("admin", "admin"), mockTicket("bob"))
+	require.NoError(t, err)
+
+	// When
+	_, err = svc.QueryTicketHistory(as("alice", "reporter"), created.ID, collection.QuerySpec{})
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+}
+
+func TestShouldQueryHistoryOfAuthorizedTicketInTrash(t *testing.T) {
+	// Given
+	svc := newTicketService(t, reporterPolicy)
+	created, err := svc.CreateTicket(as("admin", "admin"), mockTicket("alice"))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTicket(as("admin", "admin"), created.ID, ""))
+
+	// When
+	history, err := svc.QueryTicketHistory(as("alice", "reporter"), created.ID, collection.QuerySpec{})
+
+	// Then
+	require.NoError(t, err)
+	assert.Len(t, history.Results, 2)
+}
+
+func TestShouldOnlyQueryHistoryOfPurgedTicketIfAuthorizedForEveryTicket(t *testing.T) {
+	// Given
+	svc := newTicketService(t, reporterPolicy)
+	created, err := svc.CreateTicket(as("admin", "admin"), mockTicket("alice"))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTicket(as("admin", "admin"), created.ID, ""))
+	require.NoError(t, svc.PurgeTicket(as("admin", "admin"), created.ID))
+
+	// When
+	_, reporterErr := svc.QueryTicketHistory(as("alice", "reporter"), created.ID, collection.QuerySpec{})
+	history, adminErr := svc.QueryTicketHistory(as("admin", "admin"), created.ID, collection.QuerySpec{})
+
+	// Then
+	assert.ErrorAs(t, reporterErr, &authz.AuthorizationError{})
+	require.NoError(t, adminErr)
+	assert.Len(t, history.Results, 3)
+}
+
+func TestShouldRestoreTicketInTrash(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	created, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTicket(admin, created.ID, ""))
+
+	// When
+	restored, err := svc.RestoreTicket(admin, created.ID)
+
+	// Then
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	read, err := svc.ReadTicket(admin, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, restored.Version, read.Version)
+	trash, err := svc.QueryTrashedTickets(admin, collection.QuerySpec{})
+	require.NoError(t, err)
+	assert.Empty(t, trash.Results)
+}
+
+func TestShouldNotRestoreTicketNotInTrash(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	created, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+
+	// When
+	_, liveErr := svc.RestoreTicket(admin, created.ID)
+	_, missingErr := svc.RestoreTicket(admin, "missing")
+
+	// Then
+	assert.ErrorAs(t, liveErr, &pkgrepository.NotFoundError{})
+	assert.ErrorAs(t, missingErr, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldPurgeTicketInTrash(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	created, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTicket(admin, created.ID, ""))
+
+	// When
+	err = svc.PurgeTicket(admin, created.ID)
+
+	// Then
+	require.NoError(t, err)
+	_, err = svc.RestoreTicket(admin, created.ID)
+	assert.ErrorAs(t, err, &pkgrepository.NotFoundError{})
+}
+
+func TestShouldNotPurgeTicketNotInTrash(t *testing.T) {
+	// Given
+	svc := newTicketService(t, adminPolicy)
+	admin := as("admin", "admin")
+	created, err := svc.CreateTicket(admin, mockTicket("alice"))
+	require.NoError(t, err)
+
+	// When
+	liveErr := svc.PurgeTicket(admin, created.ID)
+	missingErr := svc.PurgeTicket(admin, "missing")
+
+	// Then
+	assert.ErrorAs(t, liveErr, &pkgrepository.NotFoundError{})
+	assert.ErrorAs(t, missingErr, &pkgrepository.NotFoundError{})
+	_, err = svc.ReadTicket(admin, created.ID)
+	assert.NoError(t, err)
+}
+
+func TestShouldQueryTicketsByStatusNotInWorkflow(t *testing.T) {
+	// Given
+	store := repository.NewMemoryStore()
+	tickets := repository.NewMemoryTicketRepository(store)
+	svc := service.NewTicketService(tickets, repository.NewMemoryHistoryRepository(), authz.AlwaysAuthorize{}, workflow.Default)
+	tx, err := tickets.StartTx(context.Background(), false)
+	require.NoError(t, err)
+	legacy := mockTicket("alice")
+	legacy.Status = "Done"
+	legacy, err = tickets.Create(tx, legacy)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	// When
+	page, err := svc.QueryTickets(as("admin", "admin"), collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: "status", Operator: cql.OpEq, Value: "Done"}},
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, legacy.ID, page.Results[0].ID)
+}
+
+// newTicketService creates a TicketService storing tickets in memory and enforcing the policy.
+func newTicketService(t *testing.T, policy authz.Policy) service.TicketService {
+	store := repository.NewMemoryStore()
+	authorizer, err := authz.NewRoleAuthorizer(policy, service.AuthorizedResources()...)
+	require.NoError(t, err)
+
+	return service.NewTicketService(repository.NewMemoryTicketRepository(store), repository.NewMemoryHistoryRepository(), authorizer, workflow.Default)
+}
+
+// as returns a context carrying a principal with the subject and roles.
+func as(subject string, roles ...string) context.Context {
+	return authz.WithPrincipal(context.Background(), authz.Principal{Subject: subject, Roles: roles})
+}
+
+func mockTicket(reporter string) ticket.TicketWithMetadata {
+	return ticket.TicketWithMetadata{Ticket: ticket.Ticket{Summary: "mock summary", Reporter: reporter}}
+}
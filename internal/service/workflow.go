@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
+)
+
+type WorkflowService struct {
+	authorizer authz.Authorizer
+	workflow   workflow.Workflow
+}
+
+func NewWorkflowService(w workflow.Workflow, a authz.Authorizer) WorkflowService {
+	return WorkflowService{workflow: w, authorizer: a}
+}
+
+func (svc WorkflowService) ReadWorkflow(context context.Context) (workflow.Workflow, error) {
+	if err := svc.authorizer.IsAuthorized(context, "ReadWorkflow"); err != nil {
+		return workflow.Workflow{}, err
+	}
+
+	return svc.workflow, nil
+}
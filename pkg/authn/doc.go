@@ -0,0 +1,4 @@
+// Authn provides a common pattern for identifying the caller of an operation from a
+// signed JSON Web Token (JWT).
+
+package authn
@@ -0,0 +1,10 @@
+package authn
+
+// AuthenticationError is returned when the caller could not be identified.
+type AuthenticationError struct {
+	Message string
+}
+
+func (ae AuthenticationError) Error() string {
+	return ae.Message
+}
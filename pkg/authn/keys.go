@@ -0,0 +1,157 @@
+package authn
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet holds the keys used to verify token signatures.
+// Each key may only verify tokens signed with the algorithm matching its type:
+// HS256 for secret keys and RS256 for RSA public keys.
+type KeySet struct {
+	keys map[string]key
+}
+
+// key is a verification key and the only signing method it may be used with.
+type key struct {
+	method jwt.SigningMethod
+	value  any
+}
+
+// jwks is a JSON Web Key Set as described in RFC 7517.
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		K   string `json:"k"`
+	} `json:"keys"`
+}
+
+// LoadKeyFile reads a single verification key from a file. A file containing a PEM encoded
+// RSA public key verifies RS256 tokens, otherwise the file content is a secret that
+// verifies HS256 tokens. Returns the key set, or error.
+func LoadKeyFile(filename string) (KeySet, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return KeySet{}, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(bytes)), "-----BEGIN") {
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(bytes)
+		if err != nil {
+			return KeySet{}, fmt.Errorf("failed to parse RSA public key: %w", err)
+		}
+		return KeySet{keys: map[string]key{"": {method: jwt.SigningMethodRS256, value: publicKey}}}, nil
+	}
+
+	secret := []byte(strings.TrimSpace(string(bytes)))
+	if len(secret) == 0 {
+		return KeySet{}, fmt.Errorf("empty key file: %s", filename)
+	}
+	return KeySet{keys: map[string]key{"": {method: jwt.SigningMethodHS256, value: secret}}}, nil
+}
+
+// LoadJWKSFile reads verification keys from a file containing a JSON Web Key Set. Keys of type
+// "RSA" verify RS256 tokens and keys of type "oct" verify HS256 tokens. Tokens are matched to
+// keys by their "kid" header. Returns the key set, or error.
+func LoadJWKSFile(filename string) (KeySet, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return KeySet{}, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	set := jwks{}
+	if err := json.Unmarshal(bytes, &set); err != nil {
+		return KeySet{}, fmt.Errorf("failed to parse jwks file: %w", err)
+	}
+
+	keys := make(map[string]key)
+	for _, k := range set.Keys {
+		switch k.Kty {
+		case "RSA":
+			publicKey, err := parseRSAPublicKey(k.N, k.E)
+			if err != nil {
+				return KeySet{}, fmt.Errorf("invalid jwk %s: %w", k.Kid, err)
+			}
+			keys[k.Kid] = key{method: jwt.SigningMethodRS256, value: publicKey}
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return KeySet{}, fmt.Errorf("invalid jwk %s: %w", k.Kid, err)
+			}
+			if len(secret) == 0 {
+				return KeySet{}, fmt.Errorf("invalid jwk %s: empty key", k.Kid)
+			}
+			keys[k.Kid] = key{method: jwt.SigningMethodHS256, value: secret}
+		default:
+			return KeySet{}, fmt.Errorf("unsupported jwk type: %s", k.Kty)
+		}
+	}
+
+	if len(keys) == 0 {
+		return KeySet{}, fmt.Errorf("no keys in jwks file: %s", filename)
+	}
+
+	return KeySet{keys: keys}, nil
+}
+
+// methods returns the names of all the signing methods accepted by the key set.
+func (ks KeySet) methods() []string {
+	methods := []string{}
+	seen := map[string]bool{}
+	for _, k := range ks.keys {
+		if !seen[k.method.Alg()] {
+			seen[k.method.Alg()] = true
+			methods = append(methods, k.method.Alg())
+		}
+	}
+	return methods
+}
+
+// keyfunc returns the key for verifying the token's signature.
+func (ks KeySet) keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		k, ok = ks.keys[""]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("signing method %s not allowed for key", token.Method.Alg())
+	}
+
+	return k.value, nil
+}
+
+// parseRSAPublicKey returns an RSA public key from base64url encoded modulus and exponent.
+func parseRSAPublicKey(n string, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(eBytes)
+	if !exponent.IsInt64() || exponent.Int64() < 2 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,95 @@
+package authn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+)
+
+// Verifier validates signed JSON Web Tokens and returns the principal they identify.
+type Verifier struct {
+
+	// Keys verify token signatures.
+	Keys KeySet
+
+	// Issuer, if set, must match the "iss" claim of the token.
+	Issuer string
+
+	// Audience, if set, must be one of the "aud" claims of the token.
+	Audience string
+
+	// RolesClaim names the claim holding the principal's roles. Default is "roles".
+	RolesClaim string
+
+	// TenantClaim names the claim holding the principal's tenant. Default is "tenant".
+	TenantClaim string
+}
+
+// Verify checks the token signature and registered claims. Tokens must have a subject and
+// an expiry time. Returns the principal identified by the token, or AuthenticationError.
+func (v Verifier) Verify(token string) (authz.Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(v.Keys.methods()),
+		jwt.WithExpirationRequired(),
+	}
+	if v.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.NewParser(opts...).ParseWithClaims(token, claims, v.Keys.keyfunc); err != nil {
+		return authz.Principal{}, AuthenticationError{Message: fmt.Sprintf("invalid token: %s", err)}
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return authz.Principal{}, AuthenticationError{Message: "invalid token: missing claim: sub"}
+	}
+
+	roles, err := stringsClaim(claims, defaultString(v.RolesClaim, "roles"))
+	if err != nil {
+		return authz.Principal{}, AuthenticationError{Message: fmt.Sprintf("invalid token: %s", err)}
+	}
+
+	tenant, ok := claims[defaultString(v.TenantClaim, "tenant")].(string)
+	if !ok && claims[defaultString(v.TenantClaim, "tenant")] != nil {
+		return authz.Principal{}, AuthenticationError{Message: "invalid token: tenant claim is not a string"}
+	}
+
+	return authz.Principal{Subject: subject, Roles: roles, Tenant: tenant}, nil
+}
+
+// stringsClaim returns a claim that is either an array of strings or a space separated string.
+func stringsClaim(claims jwt.MapClaims, name string) ([]string, error) {
+	switch value := claims[name].(type) {
+	case nil:
+		return []string{}, nil
+	case string:
+		return strings.Fields(value), nil
+	case []any:
+		values := make([]string, len(value))
+		for i, v := range value {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s claim is not an array of strings", name)
+			}
+			values[i] = s
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%s claim is not an array of strings", name)
+	}
+}
+
+func defaultString(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,254 @@
+package authn_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/authn"
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+)
+
+var secret = []byte("mock secret")
+
+func TestShouldVerifyHS256Token(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret)}
+	token := mustSign(t, jwt.SigningMethodHS256, secret, validClaims())
+
+	// When
+	principal, err := verifier.Verify(token)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, authz.Principal{Subject: "alice", Roles: []string{"agent", "admin"}, Tenant: "acme"}, principal)
+}
+
+func TestShouldVerifyRS256Token(t *testing.T) {
+	// Given
+	privateKey := mustGenerateRSAKey(t)
+	publicKey, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))}
+	token := mustSign(t, jwt.SigningMethodRS256, privateKey, validClaims())
+
+	// When
+	principal, err := verifier.Verify(token)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+}
+
+func TestShouldVerifyTokenWithJWKS(t *testing.T) {
+	// Given
+	privateKey := mustGenerateRSAKey(t)
+	verifier := authn.Verifier{Keys: mustLoadJWKSFile(t, map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac", "k": base64.RawURLEncoding.EncodeToString(secret)},
+		{
+			"kty": "RSA",
+			"kid": "rsa",
+			"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		},
+	}})}
+	rsaToken := mustSignWithKid(t, jwt.SigningMethodRS256, privateKey, "rsa", validClaims())
+	hmacToken := mustSignWithKid(t, jwt.SigningMethodHS256, secret, "hmac", validClaims())
+
+	// When
+	_, rsaErr := verifier.Verify(rsaToken)
+	_, hmacErr := verifier.Verify(hmacToken)
+
+	// Then
+	assert.NoError(t, rsaErr)
+	assert.NoError(t, hmacErr)
+}
+
+func TestShouldRejectTokenWithUnknownKid(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadJWKSFile(t, map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac", "k": base64.RawURLEncoding.EncodeToString(secret)},
+	}})}
+	token := mustSignWithKid(t, jwt.SigningMethodHS256, secret, "other", validClaims())
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+}
+
+func TestShouldRejectTokenWithWrongSignature(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret)}
+	token := mustSign(t, jwt.SigningMethodHS256, []byte("other secret"), validClaims())
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+}
+
+func TestShouldRejectTokenWithUnexpectedAlgorithm(t *testing.T) {
+	// Given
+	privateKey := mustGenerateRSAKey(t)
+	publicKey, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, publicPEM)}
+	token := mustSign(t, jwt.SigningMethodHS256, publicPEM, validClaims())
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+}
+
+func TestShouldRejectExpiredToken(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret)}
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	token := mustSign(t, jwt.SigningMethodHS256, secret, claims)
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+	assert.Contains(t, err.Error(), "expired")
+}
+
+func TestShouldRejectTokenWithoutExpiry(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret)}
+	claims := validClaims()
+	delete(claims, "exp")
+	token := mustSign(t, jwt.SigningMethodHS256, secret, claims)
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+}
+
+func TestShouldRejectTokenWithoutSubject(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret)}
+	claims := validClaims()
+	delete(claims, "sub")
+	token := mustSign(t, jwt.SigningMethodHS256, secret, claims)
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+	assert.Contains(t, err.Error(), "sub")
+}
+
+func TestShouldRejectTokenWithWrongIssuer(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret), Issuer: "https://issuer.example.com"}
+	token := mustSign(t, jwt.SigningMethodHS256, secret, validClaims())
+
+	// When
+	_, err := verifier.Verify(token)
+
+	// Then
+	assert.ErrorAs(t, err, &authn.AuthenticationError{})
+}
+
+func TestShouldReadRolesFromConfiguredClaim(t *testing.T) {
+	// Given
+	verifier := authn.Verifier{Keys: mustLoadKeyFile(t, secret), RolesClaim: "scope"}
+	claims := validClaims()
+	claims["scope"] = "read write"
+	token := mustSign(t, jwt.SigningMethodHS256, secret, claims)
+
+	// When
+	principal, err := verifier.Verify(token)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "write"}, principal.Roles)
+}
+
+func TestShouldRejectJWKSWithEmptySecretKey(t *testing.T) {
+	for name, jwk := range map[string]map[string]string{
+		"missing": {"kty": "oct", "kid": "hmac"},
+		"empty":   {"kty": "oct", "kid": "hmac", "k": ""},
+	} {
+		// Given
+		content, err := json.Marshal(map[string]any{"keys": []map[string]string{jwk}})
+		require.NoError(t, err)
+		filename := filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(filename, content, 0o600))
+
+		// When
+		_, err = authn.LoadJWKSFile(filename)
+
+		// Then
+		assert.ErrorContains(t, err, "empty key", name)
+	}
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":    "alice",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"roles":  []string{"agent", "admin"},
+		"tenant": "acme",
+	}
+}
+
+func mustSign(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	return mustSignWithKid(t, method, key, "", claims)
+}
+
+func mustSignWithKid(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func mustGenerateRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func mustLoadKeyFile(t *testing.T, content []byte) authn.KeySet {
+	filename := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(filename, content, 0o600))
+	keys, err := authn.LoadKeyFile(filename)
+	require.NoError(t, err)
+	return keys
+}
+
+func mustLoadJWKSFile(t *testing.T, jwks any) authn.KeySet {
+	content, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	filename := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(filename, content, 0o600))
+	keys, err := authn.LoadJWKSFile(filename)
+	require.NoError(t, err)
+	return keys
+}
@@ -1,6 +1,10 @@
 package authz
 
-import "context"
+import (
+	"context"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
 
 // Authorizer describes a common pattern for authorizing operations.
 // Each implementation of Authorizer determines the authorization mechanisms used.
@@ -11,6 +15,24 @@ type Authorizer interface {
 	IsAuthorized(context.Context, Operation) error
 }
 
+// ResourceAuthorizer extends Authorizer with checks against the resources targeted by an operation.
+// IsAuthorized returns nil if the operation may be authorized for at least one resource, so callers
+// must also check each resource with IsAuthorizedResource, or scope queries with Scope.
+type ResourceAuthorizer interface {
+	Authorizer
+
+	// IsAuthorizedResource performs the relevant authorization checks for the operation on a resource
+	// using the request context. before is the resource before the operation and after is the resource
+	// as the operation will leave it; either is nil if there is no such resource.
+	// If not authorized then AuthorizationError is returned.
+	IsAuthorizedResource(ctx context.Context, operation Operation, before any, after any) error
+
+	// Scope returns the filters that restrict a query operation to the resources the context principal
+	// may see. Returns no filters if all resources may be seen. If not authorized for any resource then
+	// AuthorizationError is returned.
+	Scope(ctx context.Context, operation Operation) ([]collection.Expr, error)
+}
+
 // Operation represents a distinct function of the system that must be authorized before execution.
 type Operation string
 
@@ -18,9 +40,19 @@ type Operation string
 type AlwaysAuthorize struct {
 }
 
-var _ Authorizer = (*AlwaysAuthorize)(nil)
+var _ ResourceAuthorizer = (*AlwaysAuthorize)(nil)
 
 // Always returns nil i.e. the operation is authorized.
 func (a AlwaysAuthorize) IsAuthorized(_ context.Context, operation Operation) error {
 	return nil
 }
+
+// Always returns nil i.e. the operation is authorized for the resource.
+func (a AlwaysAuthorize) IsAuthorizedResource(_ context.Context, _ Operation, _ any, _ any) error {
+	return nil
+}
+
+// Always returns no filters i.e. all resources may be seen.
+func (a AlwaysAuthorize) Scope(_ context.Context, _ Operation) ([]collection.Expr, error) {
+	return nil, nil
+}
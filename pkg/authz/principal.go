@@ -0,0 +1,39 @@
+package authz
+
+import "context"
+
+// Principal identifies the caller on whose behalf an operation is performed.
+type Principal struct {
+
+	// Subject uniquely identifies the caller.
+	Subject string
+
+	// Roles lists the roles granted to the caller.
+	Roles []string
+
+	// Tenant identifies the organisation the caller belongs to.
+	Tenant string
+}
+
+// Anonymous is the principal of callers that have not been identified.
+var Anonymous = Principal{Subject: "anonymous", Roles: []string{}}
+
+// IsAnonymous returns true if the caller has not been identified.
+func (p Principal) IsAnonymous() bool {
+	return p.Subject == Anonymous.Subject
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context carrying the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by the context, or Anonymous if there is none.
+func PrincipalFromContext(ctx context.Context) Principal {
+	if principal, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return principal
+	}
+	return Anonymous
+}
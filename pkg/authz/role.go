@@ -0,0 +1,373 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+// AnyOperation may be granted to a role to authorize every operation.
+const AnyOperation Operation = "*"
+
+// Condition values replaced by properties of the context principal.
+const (
+	SubjectValue = "$subject"
+	TenantValue  = "$tenant"
+)
+
+// Policy describes the operations each role is authorized to perform.
+type Policy struct {
+
+	// Roles maps a role to the operations granted to it on every resource.
+	Roles map[string][]Operation `json:"roles" yaml:"roles"`
+
+	// Rules grant operations to roles only on resources matching conditions.
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule grants operations to roles on the resources that match its conditions.
+type Rule struct {
+
+	// Roles lists the roles granted the operations.
+	Roles []string `json:"roles" yaml:"roles"`
+
+	// Operations lists the operations granted.
+	Operations []Operation `json:"operations" yaml:"operations"`
+
+	// Before lists conditions, in CQL filter syntax, the resource must match before the operation.
+	// Query operations are scoped to the resources matching these conditions.
+	Before []string `json:"before" yaml:"before"`
+
+	// After lists conditions, in CQL filter syntax, the resource must match after the operation.
+	After []string `json:"after" yaml:"after"`
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file. Returns the policy, or error.
+func LoadPolicy(filename string) (Policy, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	p := Policy{}
+	if err := yaml.Unmarshal(bytes, &p); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	if len(p.Roles) == 0 && len(p.Rules) == 0 {
+		return Policy{}, fmt.Errorf("invalid policy %s: missing field: roles or rules", filename)
+	}
+
+	return p, nil
+}
+
+// Resource describes a kind of resource authorized by a RoleAuthorizer: the operations on it, and the
+// capabilities of its fields, which the conditions of rules granting those operations must filter by.
+type Resource struct {
+	Operations []Operation
+	Fields     map[string]collection.FieldCapability
+}
+
+// RoleAuthorizer is an implementation of ResourceAuthorizer that authorizes an operation if it is
+// granted by the policy to any of the roles of the context principal.
+//
+// Conditions of rules may use the values $subject and $tenant to refer to the context principal.
+// A principal granted an operation by more than one conditional rule is scoped to resources
+// matching the conditions of any of those rules.
+//
+// Resources are matched against the conditions of operations on a described Resource as they would
+// be by a query filter: values are converted to the types of their fields, relative times are
+// resolved at each check, and enum values are compared by their order. The conditions of other
+// operations are compared as text.
+type RoleAuthorizer struct {
+	policy Policy
+	rules  []rule
+}
+
+// rule is a Rule with parsed conditions.
+type rule struct {
+	roles      []string
+	operations []Operation
+	before     []collection.Expr
+	after      []collection.Expr
+
+	// fields maps the operations granted on described resources to the capabilities of the fields
+	// of the resource, which the conditions have been validated against.
+	fields map[Operation]map[string]collection.FieldCapability
+}
+
+var _ ResourceAuthorizer = (*RoleAuthorizer)(nil)
+
+// NewRoleAuthorizer creates a new RoleAuthorizer that enforces the given policy on the described
+// resources. Returns error if a condition of the policy is not a valid CQL filter, or is not a valid
+// filter of a resource of an operation granted by its rule. Rules granting every operation must be
+// valid for every resource.
+func NewRoleAuthorizer(policy Policy, resources ...Resource) (RoleAuthorizer, error) {
+	rules := make([]rule, len(policy.Rules))
+
+	for i, r := range policy.Rules {
+		before, err := parseConditions(r.Before)
+		if err != nil {
+			return RoleAuthorizer{}, fmt.Errorf("invalid policy rule %d: %w", i, err)
+		}
+
+		after, err := parseConditions(r.After)
+		if err != nil {
+			return RoleAuthorizer{}, fmt.Errorf("invalid policy rule %d: %w", i, err)
+		}
+
+		rules[i] = rule{roles: r.Roles, operations: r.Operations, before: before, after: after, fields: map[Operation]map[string]collection.FieldCapability{}}
+		for _, resource := range resources {
+			if err := rules[i].describe(resource); err != nil {
+				return RoleAuthorizer{}, fmt.Errorf("invalid policy rule %d: %w", i, err)
+			}
+		}
+	}
+
+	return RoleAuthorizer{policy: policy, rules: rules}, nil
+}
+
+// describe records the field capabilities of the resource for the operations on it granted by the
+// rule. Returns QueryError if the conditions are not valid filters of the resource.
+func (r rule) describe(resource Resource) error {
+	granted := []Operation{}
+	for _, o := range resource.Operations {
+		if slices.Contains(r.operations, o) || slices.Contains(r.operations, AnyOperation) {
+			granted = append(granted, o)
+		}
+	}
+	if len(granted) == 0 {
+		return nil
+	}
+
+	if _, err := collection.ValidateFilters(r.before, resource.Fields); err != nil {
+		return err
+	}
+	if _, err := collection.ValidateFilters(r.after, resource.Fields); err != nil {
+		return err
+	}
+
+	for _, o := range granted {
+		r.fields[o] = resource.Fields
+	}
+	return nil
+}
+
+// Returns nil if the operation is granted to a role of the context principal on any resource,
+// otherwise AuthorizationError.
+func (a RoleAuthorizer) IsAuthorized(ctx context.Context, operation Operation) error {
+	principal := PrincipalFromContext(ctx)
+
+	if a.isGranted(principal, operation) || len(a.rulesFor(principal, operation)) > 0 {
+		return nil
+	}
+
+	return notAuthorized(principal, operation)
+}
+
+// Returns nil if the operation is granted to a role of the context principal on every resource,
+// or by a rule whose conditions the resource matches, otherwise AuthorizationError.
+func (a RoleAuthorizer) IsAuthorizedResource(ctx context.Context, operation Operation, before any, after any) error {
+	principal := PrincipalFromContext(ctx)
+
+	if a.isGranted(principal, operation) {
+		return nil
+	}
+
+	for _, r := range a.rulesFor(principal, operation) {
+		fields := r.fields[operation]
+		if matches(r.before, fields, before, principal) && matches(r.after, fields, after, principal) {
+			return nil
+		}
+	}
+
+	return notAuthorized(principal, operation)
+}
+
+// Returns the conditions that resources must match before the operation for the context principal.
+func (a RoleAuthorizer) Scope(ctx context.Context, operation Operation) ([]collection.Expr, error) {
+	principal := PrincipalFromContext(ctx)
+
+	if a.isGranted(principal, operation) {
+		return nil, nil
+	}
+
+	rules := a.rulesFor(principal, operation)
+	if len(rules) == 0 {
+		return nil, notAuthorized(principal, operation)
+	}
+
+	scope := collection.OrExpr{}
+	for _, r := range rules {
+		if len(r.before) == 0 {
+			return nil, nil
+		}
+		conditions := collection.AndExpr{}
+		for _, c := range r.before {
+			conditions = append(conditions, bind(c, principal))
+		}
+		scope = append(scope, conditions)
+	}
+
+	if len(scope) == 1 {
+		return scope[0].(collection.AndExpr), nil
+	}
+	return []collection.Expr{scope}, nil
+}
+
+// isGranted returns true if the operation is granted to a role of the principal on every resource.
+func (a RoleAuthorizer) isGranted(principal Principal, operation Operation) bool {
+	for _, role := range principal.Roles {
+		granted := a.policy.Roles[role]
+		if slices.Contains(granted, operation) || slices.Contains(granted, AnyOperation) {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesFor returns the rules granting the operation to a role of the principal.
+func (a RoleAuthorizer) rulesFor(principal Principal, operation Operation) []rule {
+	rules := []rule{}
+	for _, r := range a.rules {
+		if !slices.Contains(r.operations, operation) && !slices.Contains(r.operations, AnyOperation) {
+			continue
+		}
+		for _, role := range principal.Roles {
+			if slices.Contains(r.roles, role) {
+				rules = append(rules, r)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// matches returns true if the resource matches all conditions. A missing resource only matches no
+// conditions. Unless fieldCapabilities is nil, the conditions are converted to the types of the fields
+// after binding principal values, and enum values are compared by their order.
+func matches(conditions []collection.Expr, fieldCapabilities map[string]collection.FieldCapability, resource any, principal Principal) bool {
+	if len(conditions) == 0 {
+		return true
+	}
+	if resource == nil {
+		return false
+	}
+
+	fields, err := toFields(resource)
+	if err != nil {
+		return false
+	}
+
+	bound := make([]collection.Expr, len(conditions))
+	for i, c := range conditions {
+		bound[i] = bind(c, principal)
+	}
+	if fieldCapabilities != nil {
+		if bound, err = collection.ValidateFilters(bound, fieldCapabilities); err != nil {
+			return false
+		}
+	}
+
+	ordered := orderedFields(fieldCapabilities)
+	for _, c := range bound {
+		if !cql.MatchOrdered(c, fields, ordered) {
+			return false
+		}
+	}
+	return true
+}
+
+// orderedFields returns the values in order of each enum field.
+func orderedFields(fieldCapabilities map[string]collection.FieldCapability) map[string][]string {
+	ordered := map[string][]string{}
+	for field, f := range fieldCapabilities {
+		if f.Type == collection.TypeEnum {
+			ordered[field] = f.Values
+		}
+	}
+	return ordered
+}
+
+// bind replaces principal values in the filters of a condition.
+func bind(condition collection.Expr, principal Principal) collection.Expr {
+	switch c := condition.(type) {
+	case collection.FilterExpr:
+		if list, ok := c.Value.([]string); ok {
+			bound := make([]string, len(list))
+			for i, v := range list {
+				bound[i] = bindValue(v, principal)
+			}
+			c.Value = bound
+		} else if v, ok := c.Value.(string); ok {
+			c.Value = bindValue(v, principal)
+		}
+		return c
+	case collection.AndExpr:
+		bound := collection.AndExpr{}
+		for _, x := range c {
+			bound = append(bound, bind(x, principal))
+		}
+		return bound
+	case collection.OrExpr:
+		bound := collection.OrExpr{}
+		for _, x := range c {
+			bound = append(bound, bind(x, principal))
+		}
+		return bound
+	case collection.NotExpr:
+		return collection.NotExpr{Expr: bind(c.Expr, principal)}
+	default:
+		return condition
+	}
+}
+
+// bindValue replaces a principal value.
+func bindValue(value string, principal Principal) string {
+	switch value {
+	case SubjectValue:
+		return principal.Subject
+	case TenantValue:
+		return principal.Tenant
+	default:
+		return value
+	}
+}
+
+// toFields returns the JSON properties of a resource.
+func toFields(resource any) (map[string]any, error) {
+	bytes, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	err = json.Unmarshal(bytes, &fields)
+	return fields, err
+}
+
+// parseConditions parses conditions in CQL filter syntax.
+func parseConditions(conditions []string) ([]collection.Expr, error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	query, err := cql.ParseQuery(url.Values{cql.ParamFilter: conditions})
+	if err != nil {
+		return nil, err
+	}
+
+	return query.Filters, nil
+}
+
+func notAuthorized(principal Principal, operation Operation) error {
+	return AuthorizationError{Message: fmt.Sprintf("%s is not authorized to %s", principal.Subject, operation)}
+}
@@ -0,0 +1,386 @@
+package authz_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/authz"
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+var policy = authz.Policy{Roles: map[string][]authz.Operation{
+	"viewer": {"QueryTickets", "ReadTicket"},
+	"agent":  {"QueryTickets", "ReadTicket", "CreateTicket", "UpdateTicket"},
+	"admin":  {authz.AnyOperation},
+}}
+
+var rulesPolicy = authz.Policy{Rules: []authz.Rule{
+	{
+		Roles:      []string{"reporter"},
+		Operations: []authz.Operation{"QueryTickets", "ReadTicket", "UpdateTicket"},
+		Before:     []string{"reporter==$subject"},
+	},
+	{
+		Roles:      []string{"agent"},
+		Operations: []authz.Operation{"UpdateTicket"},
+		After:      []string{"status!=closed"},
+	},
+	{
+		Roles:      []string{"agent"},
+		Operations: []authz.Operation{"UpdateTicket"},
+		Before:     []string{"assignee==$subject"},
+	},
+}}
+
+type resource struct {
+	Reporter string `json:"reporter"`
+	Assignee string `json:"assignee"`
+	Status   string `json:"status"`
+}
+
+func TestShouldAuthorizeOperationGrantedToRole(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"viewer"}})
+
+	// When
+	err := authorizer.IsAuthorized(ctx, "ReadTicket")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAuthorizeOperationGrantedToAnyRole(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"viewer", "agent"}})
+
+	// When
+	err := authorizer.IsAuthorized(ctx, "UpdateTicket")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAuthorizeAnyOperation(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"admin"}})
+
+	// When
+	err := authorizer.IsAuthorized(ctx, "DeleteTicket")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldNotAuthorizeOperationNotGranted(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"viewer", "unknown"}})
+
+	// When
+	err := authorizer.IsAuthorized(ctx, "DeleteTicket")
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+	assert.Contains(t, err.Error(), "alice")
+	assert.Contains(t, err.Error(), "DeleteTicket")
+}
+
+func TestShouldNotAuthorizeAnonymous(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+
+	// When
+	err := authorizer.IsAuthorized(context.Background(), "QueryTickets")
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+}
+
+func TestShouldAuthorizeOperationGrantedByRule(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, rulesPolicy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"reporter"}})
+
+	// When
+	err := authorizer.IsAuthorized(ctx, "UpdateTicket")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAuthorizeResourceMatchingRule(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, rulesPolicy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"reporter"}})
+	before := resource{Reporter: "alice", Status: "open"}
+	after := resource{Reporter: "alice", Status: "closed"}
+
+	// When
+	err := authorizer.IsAuthorizedResource(ctx, "UpdateTicket", before, after)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldNotAuthorizeResourceNotMatchingRule(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, rulesPolicy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"reporter"}})
+
+	// When
+	err := authorizer.IsAuthorizedResource(ctx, "ReadTicket", resource{Reporter: "bob"}, nil)
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+}
+
+func TestShouldOnlyAuthorizeAssigneeToClose(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, rulesPolicy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"agent"}})
+
+	// When
+	progressErr := authorizer.IsAuthorizedResource(ctx, "UpdateTicket",
+		resource{Assignee: "bob", Status: "open"}, resource{Assignee: "bob", Status: "in_progress"})
+	closeOtherErr := authorizer.IsAuthorizedResource(ctx, "UpdateTicket",
+		resource{Assignee: "bob", Status: "open"}, resource{Assignee: "bob", Status: "closed"})
+	closeOwnErr := authorizer.IsAuthorizedResource(ctx, "UpdateTicket",
+		resource{Assignee: "alice", Status: "open"}, resource{Assignee: "alice", Status: "closed"})
+
+	// Then
+	assert.NoError(t, progressErr)
+	assert.ErrorAs(t, closeOtherErr, &authz.AuthorizationError{})
+	assert.NoError(t, closeOwnErr)
+}
+
+func TestShouldAuthorizeResourceGrantedToRole(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"viewer"}})
+
+	// When
+	err := authorizer.IsAuthorizedResource(ctx, "ReadTicket", resource{Reporter: "bob"}, nil)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldReturnScopeOfRule(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, rulesPolicy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"reporter"}})
+
+	// When
+	filters, err := authorizer.Scope(ctx, "QueryTickets")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.FilterExpr{Field: "reporter", Operator: cql.OpEq, Value: "alice"}}, filters)
+}
+
+func TestShouldReturnScopeOfAnyRule(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"reporter"}, Operations: []authz.Operation{"QueryTickets"}, Before: []string{"reporter==$subject"}},
+		{Roles: []string{"agent"}, Operations: []authz.Operation{"QueryTickets"}, Before: []string{"assignee==$subject", "status!=closed"}},
+	}})
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"reporter", "agent"}})
+
+	// When
+	filters, err := authorizer.Scope(ctx, "QueryTickets")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.OrExpr{
+		collection.AndExpr{collection.FilterExpr{Field: "reporter", Operator: cql.OpEq, Value: "alice"}},
+		collection.AndExpr{
+			collection.FilterExpr{Field: "assignee", Operator: cql.OpEq, Value: "alice"},
+			collection.FilterExpr{Field: "status", Operator: cql.OpNe, Value: "closed"},
+		},
+	}}, filters)
+}
+
+func TestShouldAuthorizeResourceByConditionWithBooleanLogic(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"agent"}, Operations: []authz.Operation{"ReadTicket"}, Before: []string{"assignee==$subject OR reporter==$subject"}},
+	}})
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"agent"}})
+
+	// When
+	reporterErr := authorizer.IsAuthorizedResource(ctx, "ReadTicket", resource{Reporter: "alice"}, nil)
+	otherErr := authorizer.IsAuthorizedResource(ctx, "ReadTicket", resource{Reporter: "bob"}, nil)
+
+	// Then
+	assert.NoError(t, reporterErr)
+	assert.ErrorAs(t, otherErr, &authz.AuthorizationError{})
+}
+
+func TestShouldReturnNoScopeIfGrantedToRole(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, policy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"viewer"}})
+
+	// When
+	filters, err := authorizer.Scope(ctx, "QueryTickets")
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, filters)
+}
+
+func TestShouldReturnErrorScopingOperationNotGranted(t *testing.T) {
+	// Given
+	authorizer := mustNewRoleAuthorizer(t, rulesPolicy)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"agent"}})
+
+	// When
+	_, err := authorizer.Scope(ctx, "QueryTickets")
+
+	// Then
+	assert.ErrorAs(t, err, &authz.AuthorizationError{})
+}
+
+func TestShouldReturnErrorOnInvalidCondition(t *testing.T) {
+	// Given
+	p := authz.Policy{Rules: []authz.Rule{{Roles: []string{"foo"}, Before: []string{"bar"}}}}
+
+	// When
+	_, err := authz.NewRoleAuthorizer(p)
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bar")
+}
+
+func TestShouldLoadPolicyFromFile(t *testing.T) {
+	// Given
+	filename := filepath.Join(t.TempDir(), "policy.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+roles:
+  viewer: [QueryTickets, ReadTicket]
+  admin: ["*"]
+`), 0o600))
+
+	// When
+	p, err := authz.LoadPolicy(filename)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []authz.Operation{"QueryTickets", "ReadTicket"}, p.Roles["viewer"])
+	assert.Equal(t, []authz.Operation{authz.AnyOperation}, p.Roles["admin"])
+}
+
+func TestShouldReturnErrorLoadingEmptyPolicy(t *testing.T) {
+	// Given
+	filename := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{}`), 0o600))
+
+	// When
+	_, err := authz.LoadPolicy(filename)
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "roles or rules")
+}
+
+var conditionCapabilities = map[string]collection.FieldCapability{
+	"reporter":   {Filter: true, FilterOps: cql.StringOps},
+	"assignee":   {Filter: true, FilterOps: cql.StringOps},
+	"status":     {Filter: true, FilterOps: cql.StringOps, Type: collection.TypeEnum, Values: []string{"open", "closed"}},
+	"priority":   {Filter: true, FilterOps: cql.NumberOps, Type: collection.TypeEnum, Values: []string{"low", "medium", "high", "critical"}},
+	"created_at": {Filter: true, FilterOps: cql.NumberOps, Type: collection.TypeTime},
+}
+
+var ticketResource = authz.Resource{Operations: []authz.Operation{"QueryTickets", "ReadTicket", "UpdateTicket"}, Fields: conditionCapabilities}
+
+func TestShouldAcceptValidRuleConditions(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(rulesPolicy, ticketResource)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldReturnErrorOnRuleConditionWithUnknownField(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"reporter"}, Operations: []authz.Operation{"QueryTickets"}, Before: []string{"repoter==$subject"}},
+	}}, ticketResource)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+	assert.Contains(t, err.Error(), "rule 0")
+}
+
+func TestShouldReturnErrorOnRuleConditionWithInvalidValue(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"agent"}, Operations: []authz.Operation{authz.AnyOperation}, After: []string{"status!=done"}},
+	}}, ticketResource)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+}
+
+func TestShouldNotCheckConditionsOfRulesForOtherOperations(t *testing.T) {
+	// When
+	_, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"reporter"}, Operations: []authz.Operation{"QueryComments"}, Before: []string{"author==$subject"}},
+	}}, ticketResource)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAuthorizeResourceByOrderOfEnumCondition(t *testing.T) {
+	// Given
+	authorizer, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"triager"}, Operations: []authz.Operation{"UpdateTicket"}, Before: []string{"priority<high"}},
+	}}, ticketResource)
+	require.NoError(t, err)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"triager"}})
+
+	// When
+	medium := authorizer.IsAuthorizedResource(ctx, "UpdateTicket", map[string]any{"priority": "medium"}, nil)
+	critical := authorizer.IsAuthorizedResource(ctx, "UpdateTicket", map[string]any{"priority": "critical"}, nil)
+
+	// Then
+	assert.NoError(t, medium)
+	assert.ErrorAs(t, critical, &authz.AuthorizationError{})
+}
+
+func TestShouldAuthorizeResourceByRelativeTimeCondition(t *testing.T) {
+	// Given
+	authorizer, err := authz.NewRoleAuthorizer(authz.Policy{Rules: []authz.Rule{
+		{Roles: []string{"triager"}, Operations: []authz.Operation{"UpdateTicket"}, Before: []string{"created_at>now-24h"}},
+	}}, ticketResource)
+	require.NoError(t, err)
+	ctx := authz.WithPrincipal(context.Background(), authz.Principal{Subject: "alice", Roles: []string{"triager"}})
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	old := time.Now().Add(-48 * time.Hour).Format(time.RFC3339)
+
+	// When
+	recentErr := authorizer.IsAuthorizedResource(ctx, "UpdateTicket", map[string]any{"created_at": recent}, nil)
+	oldErr := authorizer.IsAuthorizedResource(ctx, "UpdateTicket", map[string]any{"created_at": old}, nil)
+
+	// Then
+	assert.NoError(t, recentErr)
+	assert.ErrorAs(t, oldErr, &authz.AuthorizationError{})
+}
+
+func mustNewRoleAuthorizer(t *testing.T, p authz.Policy) authz.RoleAuthorizer {
+	authorizer, err := authz.NewRoleAuthorizer(p)
+	require.NoError(t, err)
+	return authorizer
+}
@@ -6,45 +6,71 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/grantjforrester/go-ticket/pkg/collection"
 )
 
 // symbols
 const (
-	ParamPage   string               = "page"
-	ParamSize   string               = "size"
-	ParamSort   string               = "sort"
-	ParamFilter string               = "filter"
-	SortAsc     collection.Direction = "asc"
-	SortDesc    collection.Direction = "desc"
-	OpEq        collection.Operator  = "=="
-	OpNe        collection.Operator  = "!="
-	OpGt        collection.Operator  = ">"
-	OpLt        collection.Operator  = "<"
-	OpGe        collection.Operator  = ">="
-	OpLe        collection.Operator  = "<="
+	ParamPage    string               = "page"
+	ParamSize    string               = "size"
+	ParamSort    string               = "sort"
+	ParamFilter  string               = "filter"
+	ParamCount   string               = "count"
+	ParamCursor  string               = "cursor"
+	ParamFields  string               = "fields"
+	ParamBy      string               = "by"
+	ParamSearch  string               = "search"
+	SortAsc      collection.Direction = "asc"
+	SortDesc     collection.Direction = "desc"
+	OpEq         collection.Operator  = "=="
+	OpNe         collection.Operator  = "!="
+	OpGt         collection.Operator  = ">"
+	OpLt         collection.Operator  = "<"
+	OpGe         collection.Operator  = ">="
+	OpLe         collection.Operator  = "<="
+	OpIn         collection.Operator  = "=in="
+	OpOut        collection.Operator  = "=out="
+	OpLike       collection.Operator  = "=like="
+	OpILike      collection.Operator  = "=ilike="
+	OpContains   collection.Operator  = "=contains="
+	OpStartsWith collection.Operator  = "=startswith="
+	OpIsNull     collection.Operator  = "=isnull="
 )
 
 var StringOps = []collection.Operator{OpEq, OpNe}
 var BoolOps = StringOps
 var NumberOps = []collection.Operator{OpEq, OpNe, OpGt, OpLt, OpGe, OpLe}
 
+// SetOps test membership of a list of values given as (value1,value2,...).
+var SetOps = []collection.Operator{OpIn, OpOut}
+
+// PatternOps match text. =like= and =ilike= match SQL LIKE patterns, where % matches any text,
+// _ matches any character and \ escapes the next character. =ilike= ignores case.
+var PatternOps = []collection.Operator{OpLike, OpILike, OpContains, OpStartsWith}
+
+// NullOps test whether a field is null (=isnull=true) or not null (=isnull=false).
+var NullOps = []collection.Operator{OpIsNull}
+
+// Ops returns the operators of all the given groups of operators.
+func Ops(groups ...[]collection.Operator) []collection.Operator {
+	ops := []collection.Operator{}
+	for _, g := range groups {
+		ops = append(ops, g...)
+	}
+	return ops
+}
+
 var fieldPattern = `\w+`
-var valuePattern = `.+`
-var operatorPattern = fmt.Sprintf("%s|%s|%s|%s|%s|%s", OpEq, OpNe, OpGt, OpLt, OpGe, OpLe)
-var filterPattern = fmt.Sprintf("(%s)(%s)(%s)", fieldPattern, operatorPattern, valuePattern)
 var sortPattern = fmt.Sprintf("(%s) (%s|%s)", fieldPattern, SortAsc, SortDesc)
 
 func ParseQuery(urlQuery url.Values) (collection.QuerySpec, error) {
-	var (
-		frx = regexp.MustCompile(filterPattern)
-		srx = regexp.MustCompile(sortPattern)
-	)
+	srx := regexp.MustCompile(sortPattern)
 
 	q := collection.QuerySpec{}
 
-	fs, err := parseFilters(urlQuery[ParamFilter], *frx)
+	fs, err := parseFilters(urlQuery[ParamFilter])
 	if err != nil {
 		return collection.QuerySpec{}, err
 	}
@@ -68,24 +94,74 @@ func ParseQuery(urlQuery url.Values) (collection.QuerySpec, error) {
 	}
 	q.Size = sz
 
+	cnt, err := parseCount(urlQuery.Get(ParamCount))
+	if err != nil {
+		return collection.QuerySpec{}, err
+	}
+	q.Count = cnt
+
+	q.Search = strings.TrimSpace(urlQuery.Get(ParamSearch))
+
+	fds, err := parseFields(ParamFields, urlQuery[ParamFields])
+	if err != nil {
+		return collection.QuerySpec{}, err
+	}
+	q.Fields = fds
+
 	return q, nil
 }
 
-func parseFilters(filters []string, regex regexp.Regexp) ([]collection.FilterExpr, error) {
-	fltrs := []collection.FilterExpr{}
+func parseFilters(filters []string) ([]collection.Expr, error) {
+	fltrs := []collection.Expr{}
 
 	for _, f := range filters {
-		if regex.MatchString(f) {
-			parts := regex.FindStringSubmatch(f)
-			fltrs = append(fltrs, collection.FilterExpr{Field: parts[1], Operator: collection.Operator(parts[2]), Value: parts[3]})
-		} else {
-			return nil, collection.QueryError{Message: fmt.Sprintf("invalid filter: %s", f)}
+		expr, err := parseFilter(f)
+		if err != nil {
+			return nil, err
 		}
+		fltrs = append(fltrs, expr)
 	}
 
 	return fltrs, nil
 }
 
+// ParseFacets returns the facet query in the URL query parameters.
+func ParseFacets(urlQuery url.Values) (collection.FacetSpec, error) {
+	f := collection.FacetSpec{}
+
+	fs, err := parseFilters(urlQuery[ParamFilter])
+	if err != nil {
+		return collection.FacetSpec{}, err
+	}
+	f.Filters = fs
+
+	by, err := parseFields(ParamBy, urlQuery[ParamBy])
+	if err != nil {
+		return collection.FacetSpec{}, err
+	}
+	f.By = by
+
+	return f, nil
+}
+
+// parseFields returns the fields of comma separated lists of fields given by the named parameter,
+// or nil if there are none.
+func parseFields(param string, lists []string) ([]string, error) {
+	var fields []string
+	frx := regexp.MustCompile("^" + fieldPattern + "$")
+
+	for _, l := range lists {
+		for _, f := range strings.Split(l, ",") {
+			if !frx.MatchString(f) {
+				return nil, collection.QueryError{Message: fmt.Sprintf("invalid %s: %s", param, l)}
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	return fields, nil
+}
+
 func parseSorts(clauses []string, regexp regexp.Regexp) ([]collection.SortExpr, error) {
 	srts := []collection.SortExpr{}
 
@@ -126,3 +202,16 @@ func parseSize(size string) (uint64, error) {
 
 	return sz, nil
 }
+
+func parseCount(count string) (bool, error) {
+	if count == "" {
+		return false, nil
+	}
+
+	cnt, err := strconv.ParseBool(count)
+	if err != nil {
+		return false, collection.QueryError{Message: fmt.Sprintf("invalid count: %s", count)}
+	}
+
+	return cnt, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grantjforrester/go-ticket/pkg/collection"
 	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
 )
 
@@ -35,9 +36,7 @@ func TestShouldReturnFilter(t *testing.T) {
 	// Then
 	require.NoError(t, err)
 	assert.Len(t, result.Filters, 1)
-	assert.Equal(t, "foo", result.Filters[0].Field)
-	assert.Equal(t, cql.OpEq, result.Filters[0].Operator)
-	assert.Equal(t, "bar", result.Filters[0].Value)
+	assert.Equal(t, collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "bar"}, result.Filters[0])
 }
 
 func TestShouldReturnErrorOnInvalidFilter(t *testing.T) {
@@ -53,6 +52,143 @@ func TestShouldReturnErrorOnInvalidFilter(t *testing.T) {
 	assert.Contains(t, err.Error(), "foo")
 }
 
+func TestShouldReturnFilterWithPrecedence(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape("a==1 or b==2 AND NOT c!=3"))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.OrExpr{
+		collection.FilterExpr{Field: "a", Operator: cql.OpEq, Value: "1"},
+		collection.AndExpr{
+			collection.FilterExpr{Field: "b", Operator: cql.OpEq, Value: "2"},
+			collection.NotExpr{Expr: collection.FilterExpr{Field: "c", Operator: cql.OpNe, Value: "3"}},
+		},
+	}}, result.Filters)
+}
+
+func TestShouldReturnFilterWithGrouping(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape("not(a>=1 OR b<=2) AND (c>3)"))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.AndExpr{
+		collection.NotExpr{Expr: collection.OrExpr{
+			collection.FilterExpr{Field: "a", Operator: cql.OpGe, Value: "1"},
+			collection.FilterExpr{Field: "b", Operator: cql.OpLe, Value: "2"},
+		}},
+		collection.FilterExpr{Field: "c", Operator: cql.OpGt, Value: "3"},
+	}}, result.Filters)
+}
+
+func TestShouldReturnFilterWithQuotedValue(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape(`a=="foo \"and\" (bar)"`))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.FilterExpr{Field: "a", Operator: cql.OpEq, Value: `foo "and" (bar)`}}, result.Filters)
+}
+
+func TestShouldReturnFilterWithUnquotedValueToEndOfSingleComparison(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape("summary==hello world"))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.FilterExpr{Field: "summary", Operator: cql.OpEq, Value: "hello world"}}, result.Filters)
+}
+
+func TestShouldReturnFilterFieldsNamedAsKeywords(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape("not==1 and or==2"))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.AndExpr{
+		collection.FilterExpr{Field: "not", Operator: cql.OpEq, Value: "1"},
+		collection.FilterExpr{Field: "or", Operator: cql.OpEq, Value: "2"},
+	}}, result.Filters)
+}
+
+func TestShouldReturnFilterWithList(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape(`a=in=(1, "2,3" ,4) AND b=out=(x)`))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.AndExpr{
+		collection.FilterExpr{Field: "a", Operator: cql.OpIn, Value: []string{"1", "2,3", "4"}},
+		collection.FilterExpr{Field: "b", Operator: cql.OpOut, Value: []string{"x"}},
+	}}, result.Filters)
+}
+
+func TestShouldReturnFilterWithPatternAndNullOperators(t *testing.T) {
+	// Given
+	query := MustParseQuery("filter=" + url.QueryEscape(`a=like=f%o_ OR a=ilike=F% OR a=contains=a,b OR a=startswith=b OR a=isnull=TRUE`))
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{collection.OrExpr{
+		collection.FilterExpr{Field: "a", Operator: cql.OpLike, Value: "f%o_"},
+		collection.FilterExpr{Field: "a", Operator: cql.OpILike, Value: "F%"},
+		collection.FilterExpr{Field: "a", Operator: cql.OpContains, Value: "a,b"},
+		collection.FilterExpr{Field: "a", Operator: cql.OpStartsWith, Value: "b"},
+		collection.FilterExpr{Field: "a", Operator: cql.OpIsNull, Value: true},
+	}}, result.Filters)
+}
+
+func TestShouldReturnPositionOfFilterError(t *testing.T) {
+	for filter, position := range map[string]int{
+		"a==1 AND":      9,
+		"(a==1":         6,
+		"a==1)":         5,
+		"a==1 b==(2":    6,
+		"a=1":           2,
+		"a== ":          5,
+		`a=="foo`:       8,
+		"a==1 OR NOT (": 14,
+		"a=in=1":        6,
+		"a=in=(1,)":     9,
+		"a=in=(1 2)":    9,
+		"a=isnull=yes":  10,
+	} {
+		// Given
+		query := url.Values{cql.ParamFilter: []string{filter}}
+
+		// When
+		_, err := cql.ParseQuery(query)
+
+		// Then
+		qe := collection.QueryError{}
+		require.ErrorAs(t, err, &qe, filter)
+		assert.Equal(t, position, qe.Position, filter)
+		assert.Contains(t, qe.Message, filter)
+	}
+}
+
 func TestShouldReturnSort(t *testing.T) {
 	// Given
 	query := MustParseQuery("sort=foo+asc")
@@ -158,6 +294,92 @@ func TestShouldReturnErrorOnInvalidPageSize(t *testing.T) {
 	assert.Contains(t, err.Error(), "foo")
 }
 
+func TestShouldReturnCount(t *testing.T) {
+	// Given
+	query := MustParseQuery("count=true")
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.True(t, result.Count)
+}
+
+func TestShouldReturnErrorOnInvalidCount(t *testing.T) {
+	// Given
+	query := MustParseQuery("count=foo")
+
+	// When
+	_, err := cql.ParseQuery(query)
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "count")
+	assert.Contains(t, err.Error(), "foo")
+}
+
+func TestShouldReturnFields(t *testing.T) {
+	// Given
+	query := MustParseQuery("fields=foo,bar&fields=baz")
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar", "baz"}, result.Fields)
+}
+
+func TestShouldReturnErrorOnInvalidFields(t *testing.T) {
+	// Given
+	query := MustParseQuery("fields=foo,,bar")
+
+	// When
+	_, err := cql.ParseQuery(query)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+	assert.Contains(t, err.Error(), "fields")
+}
+
+func TestShouldReturnFacets(t *testing.T) {
+	// Given
+	query := MustParseQuery("by=foo&by=bar&filter=foo%3D%3Dbaz")
+
+	// When
+	result, err := cql.ParseFacets(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, result.By)
+	assert.Equal(t, []collection.Expr{collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "baz"}}, result.Filters)
+}
+
+func TestShouldReturnErrorOnInvalidFacets(t *testing.T) {
+	// Given
+	query := MustParseQuery("by=foo%20bar")
+
+	// When
+	_, err := cql.ParseFacets(query)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+	assert.Contains(t, err.Error(), "by")
+}
+
+func TestShouldReturnSearch(t *testing.T) {
+	// Given
+	query := MustParseQuery("search=+quick+fox+")
+
+	// When
+	result, err := cql.ParseQuery(query)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "quick fox", result.Search)
+}
+
 func MustParseQuery(query string) url.Values {
 	parsed, err := url.ParseQuery(query)
 	if err != nil {
@@ -0,0 +1,28 @@
+package cql
+
+import (
+	"fmt"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+// NextCursor returns the position of the last of the results of a query, or nil if the results
+// do not fill the page or are ranked by a search. The values of the cursor are the JSON properties of the last result
+// named by the sorts and key field of the query.
+func NextCursor[T any](query collection.QuerySpec, keyField string, results []T) (*collection.Cursor, error) {
+	if query.Size == 0 || uint64(len(results)) < query.Size || query.Search != "" {
+		return nil, nil
+	}
+
+	fields, err := toFields(results[len(results)-1])
+	if err != nil {
+		return nil, fmt.Errorf("reading resource fields failed: %w", err)
+	}
+
+	cursor := collection.Cursor{Sorts: query.Sorts, Values: make([]any, len(query.Sorts)), Key: fmt.Sprint(fields[keyField])}
+	for i, s := range query.Sorts {
+		cursor.Values[i] = fields[s.Field]
+	}
+
+	return &cursor, nil
+}
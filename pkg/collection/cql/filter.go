@@ -0,0 +1,349 @@
+package cql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+// A filter is parsed by the grammar:
+//
+//	filter     = or
+//	or         = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" or ")" | comparison
+//	comparison = field operator value | field set-operator "(" value { "," value } ")"
+//
+// Keywords are case insensitive. A value containing white space or parentheses, or a list value
+// containing a comma, must be quoted with double quotes, and a double quote or backslash in a
+// quoted value escaped by a backslash. The value of =isnull= must be true or false.
+//
+// As before the grammar was introduced, a filter that is a single comparison may instead have an
+// unquoted value running to the end of the filter, such as summary==hello world, provided the value
+// contains no parentheses or keywords.
+
+// operators in the order they are matched, so longer operators are matched before their prefixes.
+var operators = []collection.Operator{
+	OpStartsWith, OpContains, OpILike, OpLike, OpIsNull, OpOut, OpIn, OpEq, OpNe, OpGe, OpLe, OpGt, OpLt,
+}
+
+// filterParser is a recursive descent parser of a single filter.
+type filterParser struct {
+	input string
+	pos   int
+}
+
+// parseFilter returns the expression of a filter.
+// Returns QueryError with the position of the first syntax error in the filter.
+func parseFilter(filter string) (collection.Expr, error) {
+	p := filterParser{input: filter}
+
+	expr, err := p.parseOr()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.input) {
+			err = p.unexpected()
+		}
+	}
+	if err != nil {
+		if expr, ok := parseTrailingValue(filter); ok {
+			return expr, nil
+		}
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// parseTrailingValue returns the expression of a filter that is a single comparison whose unquoted
+// value runs to the end of the filter, or false if the filter is not such a comparison.
+func parseTrailingValue(filter string) (collection.Expr, bool) {
+	p := filterParser{input: filter}
+
+	start := p.pos
+	for p.pos < len(p.input) && isFieldByte(p.input[p.pos]) {
+		p.pos++
+	}
+	field := p.input[start:p.pos]
+
+	p.skipSpace()
+	operator, ok := p.parseOperator()
+	if field == "" || !ok || operator == OpIn || operator == OpOut || operator == OpIsNull {
+		return nil, false
+	}
+
+	p.skipSpace()
+	value := strings.TrimRightFunc(p.input[p.pos:], unicode.IsSpace)
+	if value == "" || strings.HasPrefix(value, `"`) || strings.ContainsAny(value, "()") {
+		return nil, false
+	}
+	for _, word := range strings.Fields(value) {
+		if strings.EqualFold(word, "AND") || strings.EqualFold(word, "OR") || strings.EqualFold(word, "NOT") {
+			return nil, false
+		}
+	}
+
+	return collection.FilterExpr{Field: field, Operator: operator, Value: value}, true
+}
+
+func (p *filterParser) parseOr() (collection.Expr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	or := collection.OrExpr{expr}
+	for p.keyword("OR") {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *filterParser) parseAnd() (collection.Expr, error) {
+	expr, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	and := collection.AndExpr{expr}
+	for p.keyword("AND") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *filterParser) parseUnary() (collection.Expr, error) {
+	if p.keyword("NOT") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return collection.NotExpr{Expr: expr}, nil
+	}
+
+	p.skipSpace()
+	if !p.consume("(") {
+		return p.parseComparison()
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.consume(")") {
+		return nil, p.expected(`")"`)
+	}
+
+	return expr, nil
+}
+
+func (p *filterParser) parseComparison() (collection.Expr, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isFieldByte(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.expected("field")
+	}
+	field := p.input[start:p.pos]
+
+	p.skipSpace()
+	operator, ok := p.parseOperator()
+	if !ok {
+		return nil, p.expected("operator")
+	}
+
+	p.skipSpace()
+	var value any
+	var err error
+	switch operator {
+	case OpIn, OpOut:
+		value, err = p.parseList()
+	case OpIsNull:
+		value, err = p.parseBool()
+	default:
+		value, err = p.parseValue(false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return collection.FilterExpr{Field: field, Operator: operator, Value: value}, nil
+}
+
+func (p *filterParser) parseOperator() (collection.Operator, bool) {
+	for _, op := range operators {
+		if p.consume(string(op)) {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+// parseValue returns a quoted or unquoted value. An unquoted value in a list also ends at a comma.
+func (p *filterParser) parseValue(inList bool) (string, error) {
+	if p.consume(`"`) {
+		return p.parseQuoted()
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' || (inList && r == ',') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.expected("value")
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+// parseList returns the values of a parenthesized list of one or more values.
+func (p *filterParser) parseList() ([]string, error) {
+	if !p.consume("(") {
+		return nil, p.expected(`"("`)
+	}
+
+	values := []string{}
+	for {
+		p.skipSpace()
+		value, err := p.parseValue(true)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipSpace()
+		if p.consume(")") {
+			return values, nil
+		}
+		if !p.consume(",") {
+			return nil, p.expected(`"," or ")"`)
+		}
+	}
+}
+
+// parseBool returns the value of true or false.
+func (p *filterParser) parseBool() (bool, error) {
+	start := p.pos
+	value, err := p.parseValue(false)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.pos = start
+		return false, p.expected("true or false")
+	}
+
+	return b, nil
+}
+
+// parseQuoted returns the value of a quoted string after its opening quote.
+func (p *filterParser) parseQuoted() (string, error) {
+	var value strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == '"':
+			return value.String(), nil
+		case c == '\\' && p.pos < len(p.input):
+			value.WriteByte(p.input[p.pos])
+			p.pos++
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return "", p.expected(`'"'`)
+}
+
+// keyword consumes the keyword if it is next in the input, followed by white space, a
+// parenthesis or the end of the input.
+func (p *filterParser) keyword(keyword string) bool {
+	p.skipSpace()
+	end := p.pos + len(keyword)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], keyword) {
+		return false
+	}
+	if end < len(p.input) {
+		r, _ := utf8.DecodeRuneInString(p.input[end:])
+		if !unicode.IsSpace(r) && r != '(' && r != ')' {
+			return false
+		}
+	}
+
+	p.pos = end
+	return true
+}
+
+// consume consumes the token if it is next in the input.
+func (p *filterParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+func (p *filterParser) expected(what string) error {
+	if p.pos >= len(p.input) {
+		return p.errorf("expected %s but found end", what)
+	}
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return p.errorf("expected %s but found %q", what, r)
+}
+
+func (p *filterParser) unexpected() error {
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return p.errorf("unexpected %q", r)
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return collection.QueryError{
+		Message:  fmt.Sprintf("invalid filter: %s: %s at position %d", p.input, fmt.Sprintf(format, args...), p.pos+1),
+		Position: p.pos + 1,
+	}
+}
+
+// isFieldByte returns true if c may be part of a field name.
+func isFieldByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,98 @@
+package cql
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+// link relations
+const (
+	RelFirst string = "first"
+	RelPrev  string = "prev"
+	RelNext  string = "next"
+	RelLast  string = "last"
+)
+
+// Link describes a link to another page of a collection as defined by RFC 8288.
+type Link struct {
+	Rel string
+	URL url.URL
+}
+
+// Returns the link formatted as the value of a HTTP Link header.
+func (l Link) String() string {
+	return fmt.Sprintf(`<%s>; rel="%s"`, l.URL.String(), l.Rel)
+}
+
+// PageLinks returns links to the first, previous, next and last pages relative to the page
+// returned for a query. The links repeat the query in the request URL with only the page changed.
+// Without a total count in the page there is no last link, and a next link is returned whenever
+// the page is full. For a query after a cursor only the first link and a next link to the next
+// cursor are returned. No links are returned if the query is not paged.
+func PageLinks[T any](requestURL url.URL, query collection.QuerySpec, page collection.Page[T]) []Link {
+	if query.Size == 0 {
+		return nil
+	}
+
+	if query.After != nil {
+		links := []Link{pageLink(requestURL, query.Size, RelFirst, 1)}
+		if page.NextCursor != "" {
+			links = append(links, cursorLink(requestURL, query.Size, RelNext, page.NextCursor))
+		}
+		return links
+	}
+
+	current := query.Page
+	if current == 0 {
+		current = 1
+	}
+
+	links := []Link{pageLink(requestURL, query.Size, RelFirst, 1)}
+
+	if prev := current - 1; prev > 0 {
+		if page.Pages != nil && prev > *page.Pages {
+			prev = *page.Pages
+		}
+		if prev > 0 {
+			links = append(links, pageLink(requestURL, query.Size, RelPrev, prev))
+		}
+	}
+
+	if page.Pages != nil {
+		if current < *page.Pages {
+			links = append(links, pageLink(requestURL, query.Size, RelNext, current+1))
+		}
+		if *page.Pages > 0 {
+			links = append(links, pageLink(requestURL, query.Size, RelLast, *page.Pages))
+		}
+	} else if page.Size == query.Size {
+		links = append(links, pageLink(requestURL, query.Size, RelNext, current+1))
+	}
+
+	return links
+}
+
+// returns a link to the given page of the query in the request URL.
+func pageLink(requestURL url.URL, size uint64, rel string, page uint64) Link {
+	params := requestURL.Query()
+	params.Del(ParamCursor)
+	params.Set(ParamPage, strconv.FormatUint(page, 10))
+	params.Set(ParamSize, strconv.FormatUint(size, 10))
+	requestURL.RawQuery = params.Encode()
+
+	return Link{Rel: rel, URL: requestURL}
+}
+
+// returns a link to the query in the request URL after the given cursor.
+func cursorLink(requestURL url.URL, size uint64, rel string, cursor string) Link {
+	params := requestURL.Query()
+	params.Del(ParamPage)
+	params.Set(ParamCursor, cursor)
+	params.Set(ParamSize, strconv.FormatUint(size, 10))
+	requestURL.RawQuery = params.Encode()
+
+	return Link{Rel: rel, URL: requestURL}
+}
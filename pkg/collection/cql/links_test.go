@@ -0,0 +1,123 @@
+package cql_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+func TestShouldReturnNoLinksWhenNotPaged(t *testing.T) {
+	// Given
+	u := MustParseURL("/foo?sort=bar+asc")
+	page := collection.Page[string]{Results: []string{"a"}, Size: 1}
+
+	// When
+	links := cql.PageLinks(u, collection.QuerySpec{}, page)
+
+	// Then
+	assert.Empty(t, links)
+}
+
+func TestShouldReturnAllLinksWithTotal(t *testing.T) {
+	// Given
+	u := MustParseURL("/foo?filter=bar%3D%3Dbaz&page=2&size=2")
+	query := collection.QuerySpec{Page: 2, Size: 2}
+	page := collection.Page[string]{Results: []string{"a", "b"}, Page: 2, Size: 2}.WithTotal(5, 2)
+
+	// When
+	links := cql.PageLinks(u, query, page)
+
+	// Then
+	assert.Equal(t, []string{
+		`</foo?filter=bar%3D%3Dbaz&page=1&size=2>; rel="first"`,
+		`</foo?filter=bar%3D%3Dbaz&page=1&size=2>; rel="prev"`,
+		`</foo?filter=bar%3D%3Dbaz&page=3&size=2>; rel="next"`,
+		`</foo?filter=bar%3D%3Dbaz&page=3&size=2>; rel="last"`,
+	}, linkStrings(links))
+}
+
+func TestShouldReturnNoNextLinkOnLastPage(t *testing.T) {
+	// Given
+	u := MustParseURL("/foo?page=3&size=2")
+	query := collection.QuerySpec{Page: 3, Size: 2}
+	page := collection.Page[string]{Results: []string{"a"}, Page: 3, Size: 1}.WithTotal(5, 2)
+
+	// When
+	links := cql.PageLinks(u, query, page)
+
+	// Then
+	assert.Equal(t, []string{
+		`</foo?page=1&size=2>; rel="first"`,
+		`</foo?page=2&size=2>; rel="prev"`,
+		`</foo?page=3&size=2>; rel="last"`,
+	}, linkStrings(links))
+}
+
+func TestShouldReturnNextLinkOnFullPageWithoutTotal(t *testing.T) {
+	// Given
+	u := MustParseURL("/foo?size=2")
+	query := collection.QuerySpec{Size: 2}
+	page := collection.Page[string]{Results: []string{"a", "b"}, Size: 2}
+
+	// When
+	links := cql.PageLinks(u, query, page)
+
+	// Then
+	assert.Equal(t, []string{
+		`</foo?page=1&size=2>; rel="first"`,
+		`</foo?page=2&size=2>; rel="next"`,
+	}, linkStrings(links))
+}
+
+func TestShouldReturnPrevLinkToLastPageBeyondResults(t *testing.T) {
+	// Given
+	u := MustParseURL("/foo?page=9&size=2")
+	query := collection.QuerySpec{Page: 9, Size: 2}
+	page := collection.Page[string]{Results: []string{}}.WithTotal(5, 2)
+
+	// When
+	links := cql.PageLinks(u, query, page)
+
+	// Then
+	assert.Equal(t, []string{
+		`</foo?page=1&size=2>; rel="first"`,
+		`</foo?page=3&size=2>; rel="prev"`,
+		`</foo?page=3&size=2>; rel="last"`,
+	}, linkStrings(links))
+}
+
+func MustParseURL(rawURL string) url.URL {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(err)
+	}
+	return *u
+}
+
+func linkStrings(links []cql.Link) []string {
+	s := []string{}
+	for _, l := range links {
+		s = append(s, l.String())
+	}
+	return s
+}
+
+func TestShouldReturnCursorLinksAfterCursor(t *testing.T) {
+	// Given
+	u := MustParseURL("/foo?cursor=abc&size=2")
+	query := collection.QuerySpec{Size: 2, After: &collection.Cursor{}}
+	page := collection.Page[string]{Results: []string{"a", "b"}, Size: 2, NextCursor: "def"}
+
+	// When
+	links := cql.PageLinks(u, query, page)
+
+	// Then
+	assert.Equal(t, []string{
+		`</foo?page=1&size=2>; rel="first"`,
+		`</foo?cursor=def&size=2>; rel="next"`,
+	}, linkStrings(links))
+}
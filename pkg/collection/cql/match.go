@@ -0,0 +1,274 @@
+package cql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+// truth is a value of the three-valued logic of SQL.
+type truth int
+
+const (
+	unknown truth = iota
+	isFalse
+	isTrue
+)
+
+// MatchExpr returns true if a resource, given as a map of field names to values, matches the
+// expression. The expression is evaluated as the SQL generated by SQLQuery would evaluate it, so
+// a filter of a missing or null field other than =isnull= is unknown, and so is the negation of
+// an unknown.
+func MatchExpr(expr collection.Expr, fields map[string]any) bool {
+	return evaluate(expr, fields) == isTrue
+}
+
+// MatchOrdered returns true if a resource, given as a map of field names to values, matches the
+// expression, with the values of fields with an ordered set of values compared by their position in
+// the order, as the fields of MemoryQuery.Ordered are.
+func MatchOrdered(expr collection.Expr, fields map[string]any, ordered map[string][]string) bool {
+	return MatchExpr(orderExpr(ordered, expr), orderFields(ordered, fields))
+}
+
+// Match returns true if a resource, given as a map of field names to values, matches the filter.
+// Values are compared as the SQL generated by SQLQuery would compare them: numeric, boolean and
+// time fields by value, other fields as text. A missing or null field only matches =isnull=true.
+func Match(filter collection.FilterExpr, fields map[string]any) bool {
+	return evaluateFilter(filter, fields) == isTrue
+}
+
+// evaluate returns the truth of an expression for the fields of a resource.
+func evaluate(expr collection.Expr, fields map[string]any) truth {
+	switch e := expr.(type) {
+	case collection.FilterExpr:
+		return evaluateFilter(e, fields)
+	case collection.AndExpr:
+		result := isTrue
+		for _, x := range e {
+			switch evaluate(x, fields) {
+			case isFalse:
+				return isFalse
+			case unknown:
+				result = unknown
+			}
+		}
+		return result
+	case collection.OrExpr:
+		result := isFalse
+		for _, x := range e {
+			switch evaluate(x, fields) {
+			case isTrue:
+				return isTrue
+			case unknown:
+				result = unknown
+			}
+		}
+		return result
+	case collection.NotExpr:
+		switch evaluate(e.Expr, fields) {
+		case isTrue:
+			return isFalse
+		case isFalse:
+			return isTrue
+		default:
+			return unknown
+		}
+	default:
+		panic(fmt.Sprintf("unknown query filter expression: %T", expr))
+	}
+}
+
+// evaluateFilter returns the truth of a filter for the fields of a resource.
+func evaluateFilter(filter collection.FilterExpr, fields map[string]any) truth {
+	value := fields[filter.Field]
+
+	switch filter.Operator {
+	case OpIsNull:
+		isNull, _ := strconv.ParseBool(fmt.Sprint(filter.Value))
+		return toTruth((value == nil) == isNull)
+	case OpIn, OpOut:
+		return evaluateIn(filter.Operator, value, filter.Value)
+	case OpLike, OpILike, OpContains, OpStartsWith:
+		if value == nil {
+			return unknown
+		}
+		return toTruth(likePattern(filter.Operator, fmt.Sprint(filter.Value)).MatchString(fmt.Sprint(value)))
+	}
+
+	c, ok := compare(value, filter.Value)
+	if !ok {
+		return unknown
+	}
+
+	return toTruth(matchComparison(filter.Operator, c))
+}
+
+// evaluateIn returns the truth of a field value being in (=in=) or not in (=out=) a list.
+func evaluateIn(operator collection.Operator, value any, list any) truth {
+	if value == nil {
+		return unknown
+	}
+
+	in := false
+	for _, v := range listValues(list) {
+		if c, ok := compare(value, v); ok && c == 0 {
+			in = true
+			break
+		}
+	}
+
+	return toTruth(in == (operator == OpIn))
+}
+
+// listValues returns the values of a list filter value.
+func listValues(list any) []any {
+	switch l := list.(type) {
+	case []any:
+		return l
+	case []string:
+		values := make([]any, len(l))
+		for i, v := range l {
+			values[i] = v
+		}
+		return values
+	default:
+		return []any{list}
+	}
+}
+
+// likePattern returns a regular expression matching text as the SQL LIKE pattern generated by
+// SQLQuery for the operator and filter value would.
+func likePattern(operator collection.Operator, value string) *regexp.Regexp {
+	switch operator {
+	case OpContains:
+		value = "%" + escapeLike(value) + "%"
+	case OpStartsWith:
+		value = escapeLike(value) + "%"
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("(?s)")
+	if operator == OpILike {
+		pattern.WriteString("(?i)")
+	}
+	pattern.WriteString("^")
+
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			pattern.WriteString(".*")
+		case r == '_':
+			pattern.WriteString(".")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	pattern.WriteString("$")
+
+	return regexp.MustCompile(pattern.String())
+}
+
+// matchComparison returns true if the result of a comparison satisfies the operator.
+func matchComparison(operator collection.Operator, c int) bool {
+	switch operator {
+	case OpEq:
+		return c == 0
+	case OpNe:
+		return c != 0
+	case OpLt:
+		return c < 0
+	case OpLe:
+		return c <= 0
+	case OpGt:
+		return c > 0
+	case OpGe:
+		return c >= 0
+	default:
+		panic(fmt.Sprintf("unknown query filter operator: %s", operator))
+	}
+}
+
+func toTruth(b bool) truth {
+	if b {
+		return isTrue
+	}
+	return isFalse
+}
+
+// compare returns -1, 0 or 1 as the field value is less than, equal to or greater than the filter value.
+// Returns false if the values cannot be compared.
+// A time filter value is compared with the field value as an RFC 3339 time.
+func compare(fieldValue any, filterValue any) (int, bool) {
+	if t, ok := filterValue.(time.Time); ok {
+		return compareTime(fieldValue, t)
+	}
+
+	value := fmt.Sprint(filterValue)
+
+	switch fv := fieldValue.(type) {
+	case nil:
+		return 0, false
+	case float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false
+		}
+		return compareOrdered(fv, v), true
+	case bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return 0, false
+		}
+		return compareOrdered(boolToInt(fv), boolToInt(v)), true
+	default:
+		return strings.Compare(fmt.Sprint(fv), value), true
+	}
+}
+
+// compareTime returns -1, 0 or 1 as the field value is before, at or after the time.
+// Returns false if the field value is not an RFC 3339 time.
+func compareTime(fieldValue any, t time.Time) (int, bool) {
+	var fv time.Time
+	switch v := fieldValue.(type) {
+	case time.Time:
+		fv = v
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, false
+		}
+		fv = parsed
+	default:
+		return 0, false
+	}
+
+	return fv.Compare(t), true
+}
+
+func compareOrdered[T int | float64](a T, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
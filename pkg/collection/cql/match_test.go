@@ -0,0 +1,144 @@
+package cql_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+func TestShouldMatchStringEquals(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "bar"}
+
+	// Then
+	assert.True(t, cql.Match(filter, map[string]any{"foo": "bar"}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": "baz"}))
+}
+
+func TestShouldMatchStringNotEquals(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpNe, Value: "bar"}
+
+	// Then
+	assert.False(t, cql.Match(filter, map[string]any{"foo": "bar"}))
+	assert.True(t, cql.Match(filter, map[string]any{"foo": "baz"}))
+}
+
+func TestShouldMatchNumbersByValue(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpGt, Value: "9"}
+
+	// Then
+	assert.True(t, cql.Match(filter, map[string]any{"foo": float64(10)}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": float64(9)}))
+}
+
+func TestShouldMatchTimesByValue(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpGe, Value: time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)}
+
+	// Then
+	assert.True(t, cql.Match(filter, map[string]any{"foo": "2023-01-02T04:00:00+01:00"}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": "2023-01-02T03:59:59+01:00"}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": "not a time"}))
+}
+
+func TestShouldMatchStringsAsText(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpLe, Value: "b"}
+
+	// Then
+	assert.True(t, cql.Match(filter, map[string]any{"foo": "abc"}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": "c"}))
+}
+
+func TestShouldMatchBooleans(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "true"}
+
+	// Then
+	assert.True(t, cql.Match(filter, map[string]any{"foo": true}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": false}))
+}
+
+func TestShouldNotMatchMissingOrNullField(t *testing.T) {
+	// Given
+	filter := collection.FilterExpr{Field: "foo", Operator: cql.OpNe, Value: "bar"}
+
+	// Then
+	assert.False(t, cql.Match(filter, map[string]any{}))
+	assert.False(t, cql.Match(filter, map[string]any{"foo": nil}))
+}
+
+func TestShouldMatchBooleanExpressions(t *testing.T) {
+	// Given
+	expr := collection.OrExpr{
+		collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "bar"},
+		collection.AndExpr{
+			collection.FilterExpr{Field: "baz", Operator: cql.OpGt, Value: "1"},
+			collection.NotExpr{Expr: collection.FilterExpr{Field: "baz", Operator: cql.OpEq, Value: "3"}},
+		},
+	}
+
+	// Then
+	assert.True(t, cql.MatchExpr(expr, map[string]any{"foo": "bar", "baz": 3.0}))
+	assert.True(t, cql.MatchExpr(expr, map[string]any{"foo": "bam", "baz": 2.0}))
+	assert.False(t, cql.MatchExpr(expr, map[string]any{"foo": "bam", "baz": 3.0}))
+	assert.False(t, cql.MatchExpr(expr, map[string]any{"foo": "bam", "baz": 1.0}))
+}
+
+func TestShouldNotMatchNegationOfMissingOrNullField(t *testing.T) {
+	// Given
+	expr := collection.NotExpr{Expr: collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "bar"}}
+
+	// Then
+	assert.False(t, cql.MatchExpr(expr, map[string]any{}))
+	assert.False(t, cql.MatchExpr(expr, map[string]any{"foo": nil}))
+	assert.True(t, cql.MatchExpr(collection.OrExpr{expr, collection.FilterExpr{Field: "baz", Operator: cql.OpEq, Value: "1"}},
+		map[string]any{"baz": "1"}))
+}
+
+func TestShouldMatchListMembership(t *testing.T) {
+	// Given
+	in := collection.FilterExpr{Field: "foo", Operator: cql.OpIn, Value: []string{"1", "2"}}
+	out := collection.FilterExpr{Field: "foo", Operator: cql.OpOut, Value: []string{"1", "2"}}
+
+	// Then
+	assert.True(t, cql.Match(in, map[string]any{"foo": 2.0}))
+	assert.False(t, cql.Match(in, map[string]any{"foo": 3.0}))
+	assert.False(t, cql.Match(out, map[string]any{"foo": 2.0}))
+	assert.True(t, cql.Match(out, map[string]any{"foo": 3.0}))
+	assert.False(t, cql.Match(out, map[string]any{"foo": nil}))
+}
+
+func TestShouldMatchPatterns(t *testing.T) {
+	// Given
+	fields := map[string]any{"foo": "50% Off_sale"}
+
+	// Then
+	assert.True(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpLike, Value: "5_\\% O%"}, fields))
+	assert.False(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpLike, Value: "50\\_%"}, fields))
+	assert.False(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpLike, Value: "%off%"}, fields))
+	assert.True(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpILike, Value: "%off%"}, fields))
+	assert.True(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpContains, Value: "f_s"}, fields))
+	assert.False(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpContains, Value: "f%s"}, fields))
+	assert.True(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpStartsWith, Value: "50%"}, fields))
+	assert.False(t, cql.Match(collection.FilterExpr{Field: "foo", Operator: cql.OpStartsWith, Value: "5_"}, fields))
+}
+
+func TestShouldMatchNull(t *testing.T) {
+	// Given
+	isNull := collection.FilterExpr{Field: "foo", Operator: cql.OpIsNull, Value: true}
+	notNull := collection.FilterExpr{Field: "foo", Operator: cql.OpIsNull, Value: false}
+
+	// Then
+	assert.True(t, cql.Match(isNull, map[string]any{}))
+	assert.True(t, cql.Match(isNull, map[string]any{"foo": nil}))
+	assert.False(t, cql.Match(isNull, map[string]any{"foo": ""}))
+	assert.False(t, cql.Match(notNull, map[string]any{"foo": nil}))
+	assert.True(t, cql.Match(notNull, map[string]any{"foo": ""}))
+}
@@ -0,0 +1,383 @@
+package cql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+// MemoryQuery wraps a QuerySpec and evaluates it against resources held in memory.
+// The JSON properties of each resource are its fields. Resources are filtered, sorted and paged as
+// the SQL generated by SQLQuery would be, except that text is always ordered by byte value and a
+// search only approximates SQL text search. See SearchFields.
+type MemoryQuery[T any] struct {
+	Query collection.QuerySpec
+
+	// KeyField is a unique field appended to the sorts so that resources are always returned in
+	// the same order. It is required to query after a cursor. "" is not set.
+	KeyField string
+
+	// SearchFields are the text fields searched by a query with search, in order of importance.
+	// A resource matches if its search fields contain every word of the search, ignoring case.
+	// Unlike SQL text search, words are not stemmed and search operators are not supported.
+	SearchFields []string
+
+	// Ordered maps fields with an ordered set of values to the values in order. The values of these
+	// fields are filtered and sorted by their position in the order, as SQL enum types are.
+	Ordered map[string][]string
+}
+
+// Apply returns the resources matching the query, in sorted order, limited to the requested page
+// or to those after the cursor. Resources that compare equal by the sorts keep their given order.
+func (q MemoryQuery[T]) Apply(resources []T) ([]T, error) {
+	type row struct {
+		resource T
+		fields   map[string]any
+		rank     float64
+	}
+
+	var after map[string]any
+	if q.Query.After != nil {
+		fields, err := q.cursorFields(*q.Query.After)
+		if err != nil {
+			return nil, err
+		}
+		after = fields
+	}
+
+	filters := q.orderFilters()
+	rows := []row{}
+	for _, r := range resources {
+		fields, err := toFields(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading resource fields failed: %w", err)
+		}
+		fields = orderFields(q.Ordered, fields)
+		rank, found := q.rank(fields)
+		if found && matchAll(filters, fields) && (after == nil || q.compareRows(fields, after) > 0) {
+			rows = append(rows, row{resource: r, fields: fields, rank: rank})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if c := q.compareSorts(rows[i].fields, rows[j].fields); c != 0 {
+			return c < 0
+		}
+		if rows[i].rank != rows[j].rank {
+			return rows[i].rank > rows[j].rank
+		}
+		return q.compareKeys(rows[i].fields, rows[j].fields) < 0
+	})
+
+	results := make([]T, 0, len(rows))
+	for _, r := range rows {
+		results = append(results, r.resource)
+	}
+
+	return q.page(results), nil
+}
+
+// Count returns the number of resources matching the filters of the query, ignoring paging.
+func (q MemoryQuery[T]) Count(resources []T) (uint64, error) {
+	filters := q.orderFilters()
+	count := uint64(0)
+	for _, r := range resources {
+		fields, err := toFields(r)
+		if err != nil {
+			return 0, fmt.Errorf("reading resource fields failed: %w", err)
+		}
+		if _, found := q.rank(fields); found && matchAll(filters, orderFields(q.Ordered, fields)) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Match returns how the resource matched the search of the query, or nil if the query has no
+// search. The snippet is the text of the search fields with the words of the search highlighted.
+func (q MemoryQuery[T]) Match(resource T) (*collection.Match, error) {
+	if q.Query.Search == "" {
+		return nil, nil
+	}
+
+	fields, err := toFields(resource)
+	if err != nil {
+		return nil, fmt.Errorf("reading resource fields failed: %w", err)
+	}
+
+	rank, _ := q.rank(fields)
+	return &collection.Match{Rank: rank, Snippet: highlight(q.searchText(fields), searchWords(q.Query.Search))}, nil
+}
+
+// rank returns the rank of the fields of a resource for the search of the query, and true if
+// they contain every word of the search. Words in the first search field weigh more than in
+// the others, as in the search vector of SQLQuery. Without a search, all resources match.
+func (q MemoryQuery[T]) rank(fields map[string]any) (float64, bool) {
+	if q.Query.Search == "" {
+		return 0, true
+	}
+
+	counts := map[string]float64{}
+	for i, f := range q.SearchFields {
+		weight := 0.4
+		if i == 0 {
+			weight = 1
+		}
+		for _, w := range searchWords(fieldText(fields[f])) {
+			counts[w] += weight
+		}
+	}
+
+	rank := 0.0
+	for _, w := range searchWords(q.Query.Search) {
+		if counts[w] == 0 {
+			return 0, false
+		}
+		rank += counts[w]
+	}
+
+	return rank, true
+}
+
+// searchText returns the text of the search fields of a resource.
+func (q MemoryQuery[T]) searchText(fields map[string]any) string {
+	texts := []string{}
+	for _, f := range q.SearchFields {
+		if text := fieldText(fields[f]); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// Group returns the number of resources matching the filters of the query with each value of
+// the field, ignoring paging, ordered by descending count then by value.
+func (q MemoryQuery[T]) Group(resources []T, field string) ([]collection.Bucket, error) {
+	filters := q.orderFilters()
+	buckets := []collection.Bucket{}
+	for _, r := range resources {
+		fields, err := toFields(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading resource fields failed: %w", err)
+		}
+		if !matchAll(filters, orderFields(q.Ordered, fields)) {
+			continue
+		}
+
+		i := slices.IndexFunc(buckets, func(b collection.Bucket) bool { return compareFields(b.Value, fields[field]) == 0 })
+		if i < 0 {
+			buckets = append(buckets, collection.Bucket{Value: fields[field]})
+			i = len(buckets) - 1
+		}
+		buckets[i].Count++
+	}
+
+	sort.SliceStable(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return compareFields(position(q.Ordered, field, buckets[i].Value), position(q.Ordered, field, buckets[j].Value)) < 0
+	})
+
+	return buckets, nil
+}
+
+// compareRows returns -1, 0 or 1 as the fields of resource a are ordered before, with or after
+// those of resource b by the sorts and key field.
+func (q MemoryQuery[T]) compareRows(a map[string]any, b map[string]any) int {
+	if c := q.compareSorts(a, b); c != 0 {
+		return c
+	}
+	return q.compareKeys(a, b)
+}
+
+// compareSorts returns -1, 0 or 1 as the fields of resource a are ordered before, with or after
+// those of resource b by the sorts.
+func (q MemoryQuery[T]) compareSorts(a map[string]any, b map[string]any) int {
+	for _, s := range q.Query.Sorts {
+		c := compareFields(a[s.Field], b[s.Field])
+		if mapDirection(s.Direction) == "DESC" {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareKeys returns -1, 0 or 1 as the key field of resource a is less than, equal to or
+// greater than that of resource b, or 0 if there is no key field.
+func (q MemoryQuery[T]) compareKeys(a map[string]any, b map[string]any) int {
+	if q.KeyField == "" {
+		return 0
+	}
+	return compareFields(a[q.KeyField], b[q.KeyField])
+}
+
+// cursorFields returns the fields of the resource at the position of the cursor.
+func (q MemoryQuery[T]) cursorFields(cursor collection.Cursor) (map[string]any, error) {
+	if q.KeyField == "" {
+		return nil, fmt.Errorf("query after cursor requires a key field")
+	}
+	if err := cursor.CheckSorts(q.Query.Sorts); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{q.KeyField: cursor.Key}
+	for i, s := range q.Query.Sorts {
+		fields[s.Field] = cursor.Values[i]
+	}
+	return orderFields(q.Ordered, fields), nil
+}
+
+// orderFields returns the fields of a resource with the value of each ordered field replaced by
+// its position in the order.
+func orderFields(ordered map[string][]string, fields map[string]any) map[string]any {
+	if len(ordered) == 0 {
+		return fields
+	}
+
+	positions := make(map[string]any, len(fields))
+	for f, v := range fields {
+		positions[f] = position(ordered, f, v)
+	}
+	return positions
+}
+
+// orderFilters returns the filters of the query with the values of ordered fields replaced by
+// their positions in the order.
+func (q MemoryQuery[T]) orderFilters() []collection.Expr {
+	if len(q.Ordered) == 0 {
+		return q.Query.Filters
+	}
+
+	filters := make([]collection.Expr, len(q.Query.Filters))
+	for i, f := range q.Query.Filters {
+		filters[i] = orderExpr(q.Ordered, f)
+	}
+	return filters
+}
+
+// orderExpr returns the expression with the values of ordered fields replaced by their positions.
+func orderExpr(ordered map[string][]string, expr collection.Expr) collection.Expr {
+	switch e := expr.(type) {
+	case collection.FilterExpr:
+		if e.Operator == OpIn || e.Operator == OpOut {
+			values := listValues(e.Value)
+			positions := make([]any, len(values))
+			for i, v := range values {
+				positions[i] = position(ordered, e.Field, v)
+			}
+			e.Value = positions
+		} else {
+			e.Value = position(ordered, e.Field, e.Value)
+		}
+		return e
+	case collection.AndExpr:
+		and := collection.AndExpr{}
+		for _, x := range e {
+			and = append(and, orderExpr(ordered, x))
+		}
+		return and
+	case collection.OrExpr:
+		or := collection.OrExpr{}
+		for _, x := range e {
+			or = append(or, orderExpr(ordered, x))
+		}
+		return or
+	case collection.NotExpr:
+		return collection.NotExpr{Expr: orderExpr(ordered, e.Expr)}
+	default:
+		return expr
+	}
+}
+
+// position returns the position of the value of a field in the order of the field, or the value
+// if the field is not ordered or the value is not in the order.
+func position(ordered map[string][]string, field string, value any) any {
+	text, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if i := slices.Index(ordered[field], text); i >= 0 {
+		return float64(i)
+	}
+	return value
+}
+
+// page returns the results in the requested page.
+func (q MemoryQuery[T]) page(results []T) []T {
+	offset := SQLQuery{Query: q.Query}.offset()
+	if offset >= uint64(len(results)) {
+		return results[:0]
+	}
+	results = results[offset:]
+
+	if limit := (SQLQuery{Query: q.Query}).limit(); limit > 0 && limit < uint64(len(results)) {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// matchAll returns true if the fields match every filter.
+func matchAll(filters []collection.Expr, fields map[string]any) bool {
+	for _, f := range filters {
+		if !MatchExpr(f, fields) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareFields returns -1, 0 or 1 as field value a is less than, equal to or greater than b.
+// Null values are greater than all other values, as in SQL. RFC 3339 times are compared as times.
+func compareFields(a any, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			return compareOrdered(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return compareOrdered(boolToInt(av), boolToInt(bv))
+		}
+	case string:
+		if at, err := time.Parse(time.RFC3339Nano, av); err == nil {
+			if c, ok := compareTime(b, at); ok {
+				return -c
+			}
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// toFields returns the JSON properties of a resource.
+func toFields(resource any) (map[string]any, error) {
+	bytes, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	err = json.Unmarshal(bytes, &fields)
+	return fields, err
+}
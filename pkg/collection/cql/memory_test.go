@@ -0,0 +1,266 @@
+package cql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+type resource struct {
+	Foo string  `json:"foo"`
+	Bar int     `json:"bar"`
+	Baz *string `json:"baz"`
+}
+
+var (
+	baz       = "baz"
+	resources = []resource{
+		{Foo: "c", Bar: 1},
+		{Foo: "a", Bar: 10, Baz: &baz},
+		{Foo: "b", Bar: 2},
+		{Foo: "a", Bar: 3},
+	}
+)
+
+func TestShouldReturnAllResources(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, resources, results)
+}
+
+func TestShouldReturnFilteredResources(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Filters: []collection.Expr{
+			collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "a"},
+			collection.FilterExpr{Field: "bar", Operator: cql.OpGt, Value: "5"},
+		},
+	}}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []resource{resources[1]}, results)
+}
+
+func TestShouldReturnSortedResources(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Sorts: []collection.SortExpr{
+			{Field: "foo", Direction: cql.SortAsc},
+			{Field: "bar", Direction: cql.SortDesc},
+		},
+	}}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []resource{resources[1], resources[3], resources[2], resources[0]}, results)
+}
+
+func TestShouldSortNumbersByValue(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: "bar", Direction: cql.SortAsc}},
+	}}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []resource{resources[0], resources[2], resources[3], resources[1]}, results)
+}
+
+func TestShouldSortNullsLastAscendingAndFirstDescending(t *testing.T) {
+	// Given
+	asc := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: "baz", Direction: cql.SortAsc}},
+	}}
+	desc := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: "baz", Direction: cql.SortDesc}},
+	}}
+
+	// When
+	ascResults, ascErr := asc.Apply(resources)
+	descResults, descErr := desc.Apply(resources)
+
+	// Then
+	require.NoError(t, ascErr)
+	require.NoError(t, descErr)
+	assert.Equal(t, resources[1], ascResults[0])
+	assert.Equal(t, resources[1], descResults[3])
+}
+
+func TestShouldReturnPageOfResources(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{Page: 2, Size: 3}}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []resource{resources[3]}, results)
+}
+
+func TestShouldReturnEmptyPageBeyondResources(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{Page: 3, Size: 2}}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestShouldCountFilteredResourcesIgnoringPage(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: "foo", Operator: cql.OpEq, Value: "a"}},
+		Page:    1,
+		Size:    1,
+	}}
+
+	// When
+	count, err := q.Count(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+}
+
+func TestShouldGroupFilteredResourcesByValue(t *testing.T) {
+	// Given
+	q := cql.MemoryQuery[resource]{Query: collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: "bar", Operator: cql.OpLt, Value: "10"}},
+		Size:    1,
+	}}
+
+	// When
+	foo, err := q.Group(resources, "foo")
+	require.NoError(t, err)
+	baz, err := q.Group(append(resources, resource{Foo: "d", Baz: &baz}), "baz")
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, []collection.Bucket{{Value: "a", Count: 1}, {Value: "b", Count: 1}, {Value: "c", Count: 1}}, foo)
+	assert.Equal(t, []collection.Bucket{{Value: nil, Count: 3}, {Value: "baz", Count: 1}}, baz)
+}
+
+func TestShouldReturnSearchedResourcesByRank(t *testing.T) {
+	// Given
+	fox := "the quick brown fox"
+	searched := []resource{
+		{Foo: "Fox", Bar: 1, Baz: &fox},
+		{Foo: "a quick dog", Bar: 2, Baz: &fox},
+		{Foo: "a slow dog", Bar: 3, Baz: &fox},
+		{Foo: "Fox", Bar: 4},
+	}
+	q := cql.MemoryQuery[resource]{
+		Query:        collection.QuerySpec{Search: "quick FOX"},
+		SearchFields: []string{"foo", "baz"},
+	}
+
+	// When
+	results, err := q.Apply(searched)
+	require.NoError(t, err)
+	count, err := q.Count(searched)
+	require.NoError(t, err)
+	match, err := q.Match(results[0])
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, []resource{searched[0], searched[1], searched[2]}, results)
+	assert.Equal(t, uint64(3), count)
+	assert.Equal(t, "<b>Fox</b> the <b>quick</b> brown <b>fox</b>", match.Snippet)
+	assert.Greater(t, match.Rank, 0.0)
+}
+
+func TestShouldFilterAndSortOrderedFieldsByPosition(t *testing.T) {
+	// Given
+	prioritized := []resource{{Foo: "high", Bar: 1}, {Foo: "low", Bar: 2}, {Foo: "medium", Bar: 3}}
+	q := cql.MemoryQuery[resource]{
+		Query: collection.QuerySpec{
+			Filters: []collection.Expr{collection.OrExpr{
+				collection.FilterExpr{Field: "foo", Operator: cql.OpGe, Value: "medium"},
+				collection.FilterExpr{Field: "foo", Operator: cql.OpIn, Value: []any{"low"}},
+			}},
+			Sorts: []collection.SortExpr{{Field: "foo", Direction: cql.SortDesc}},
+		},
+		Ordered: map[string][]string{"foo": {"low", "medium", "high"}},
+	}
+
+	// When
+	results, err := q.Apply(prioritized)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []resource{prioritized[0], prioritized[2], prioritized[1]}, results)
+}
+
+func TestShouldReturnResourcesAfterCursor(t *testing.T) {
+	// Given
+	sorts := []collection.SortExpr{{Field: "foo", Direction: cql.SortAsc}}
+	q := cql.MemoryQuery[resource]{KeyField: "bar", Query: collection.QuerySpec{
+		Sorts: sorts,
+		Page:  2,
+		Size:  2,
+		After: &collection.Cursor{Sorts: sorts, Values: []any{"a"}, Key: "3"},
+	}}
+
+	// When
+	results, err := q.Apply(resources)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []resource{resources[2], resources[0]}, results)
+}
+
+func TestShouldReturnCursorOfLastResultOfFullPage(t *testing.T) {
+	// Given
+	query := collection.QuerySpec{Sorts: []collection.SortExpr{{Field: "baz", Direction: cql.SortAsc}}, Size: 2}
+
+	// When
+	full, err := cql.NextCursor(query, "foo", resources[:2])
+	require.NoError(t, err)
+	partial, err := cql.NextCursor(query, "foo", resources[:1])
+	require.NoError(t, err)
+
+	// Then
+	assert.Equal(t, &collection.Cursor{Sorts: query.Sorts, Values: []any{"baz"}, Key: "a"}, full)
+	assert.Nil(t, partial)
+}
+
+func TestShouldEscapeSnippetForHTML(t *testing.T) {
+	// Given
+	script := `<script>alert("fox & 'dog'")</script>`
+	searched := []resource{{Foo: script, Bar: 1}}
+	q := cql.MemoryQuery[resource]{
+		Query:        collection.QuerySpec{Search: "fox"},
+		SearchFields: []string{"foo"},
+	}
+
+	// When
+	match, err := q.Match(searched[0])
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "&lt;script&gt;alert(&#34;<b>fox</b> &amp; &#39;dog&#39;&#34;)&lt;/script&gt;", match.Snippet)
+}
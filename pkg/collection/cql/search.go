@@ -0,0 +1,90 @@
+package cql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+)
+
+// textSearchConfig is the Postgres text search configuration used to parse searched text and searches.
+const textSearchConfig = "english"
+
+// snippet markers enclosing the matching words of a snippet, as used by default by Postgres.
+const (
+	snippetStart = "<b>"
+	snippetStop  = "</b>"
+)
+
+// snippetEscapes are the characters of snippet text replaced by HTML entities so that the only markup
+// in a snippet is the snippet markers, as html.EscapeString does.
+var snippetEscapes = []string{
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&#34;",
+	"'", "&#39;",
+}
+
+var snippetEscaper = strings.NewReplacer(snippetEscapes...)
+
+// escapeSnippetSQL returns a SQL expression escaping the text of the SQL expression as snippetEscaper
+// does. Entities are parsed by Postgres as single tokens, so do not add or split searched words.
+func escapeSnippetSQL(expr string) string {
+	for i := 0; i < len(snippetEscapes); i += 2 {
+		expr = fmt.Sprintf("replace(%s, %s, %s)", expr, sqlString(snippetEscapes[i]), sqlString(snippetEscapes[i+1]))
+	}
+	return expr
+}
+
+// sqlString returns a SQL string literal of s.
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// searchWords returns the lower case words of text.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isNotWordRune)
+}
+
+// highlight returns text escaped for HTML with each of its words that is one of the given lower case
+// words enclosed by the snippet markers.
+func highlight(text string, words []string) string {
+	var highlighted strings.Builder
+	for len(text) > 0 {
+		start := strings.IndexFunc(text, func(r rune) bool { return !isNotWordRune(r) })
+		if start < 0 {
+			highlighted.WriteString(snippetEscaper.Replace(text))
+			break
+		}
+		end := strings.IndexFunc(text[start:], isNotWordRune)
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+
+		highlighted.WriteString(snippetEscaper.Replace(text[:start]))
+		if word := text[start:end]; slices.Contains(words, strings.ToLower(word)) {
+			highlighted.WriteString(snippetStart + word + snippetStop)
+		} else {
+			highlighted.WriteString(word)
+		}
+		text = text[end:]
+	}
+
+	return highlighted.String()
+}
+
+// fieldText returns the text of a field value, or "" if it is null.
+func fieldText(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
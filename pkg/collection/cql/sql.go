@@ -2,8 +2,11 @@ package cql
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
+	"golang.org/x/exp/slices"
 
 	"github.com/grantjforrester/go-ticket/pkg/collection"
 )
@@ -13,15 +16,58 @@ type SQLQuery struct {
 	Fields []string
 	Table  string
 	Query  collection.QuerySpec
+
+	// KeyField is a unique field appended to the sorts so that rows are always returned in the
+	// same order. It is required to query after a cursor. "" is not set.
+	KeyField string
+
+	// SearchVector is a text search vector column of the table, and SearchFields the text columns
+	// it is made from. They are required for a query with search.
+	SearchVector string
+	SearchFields []string
+}
+
+// SelectFields returns the fields of a table to select for a query. If the query selects fields,
+// these are the selected fields, the required fields and the sort fields needed to position a
+// cursor, in the order of all the fields. Otherwise all the fields are returned.
+func SelectFields(query collection.QuerySpec, all []string, required ...string) []string {
+	if query.Fields == nil {
+		return all
+	}
+
+	fields := []string{}
+	for _, f := range all {
+		if slices.Contains(required, f) || slices.Contains(query.Fields, f) || slices.ContainsFunc(query.Sorts, func(s collection.SortExpr) bool { return s.Field == f }) {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
 }
 
 // Returns SQL and and arguments for placeholders.
+// If the query is after a cursor, rows after the cursor are selected by a keyset condition
+// instead of an offset. If the query has a search, the rank and snippet of each row are selected
+// after the fields, and rows are ordered by descending rank after the sorts. Snippets are escaped
+// for HTML before matching words are enclosed by the snippet markers.
 func (q SQLQuery) ToSQL() (string, []any, error) {
-	sql := sq.Select(q.Fields...).From(q.Table).OrderBy(q.orderBy()...)
+	if err := q.checkSearch(); err != nil {
+		return "", nil, err
+	}
 
-	for _, f := range q.Query.Filters {
-		foo := mapFilter(f)
-		sql = sql.Where(foo)
+	sql := q.where(sq.Select(q.Fields...).From(q.Table).OrderBy(q.orderBy()...))
+
+	if q.Query.Search != "" {
+		sql = sql.Column(q.searchExpr("ts_rank(%[1]s, %[2]s) AS rank")).
+			Column(q.searchExpr(fmt.Sprintf("ts_headline('%%[3]s', %s, %%[2]s) AS snippet", escapeSnippetSQL("concat_ws(' ', %[4]s)"))))
+	}
+
+	if q.Query.After != nil {
+		keyset, err := q.keyset(*q.Query.After)
+		if err != nil {
+			return "", nil, err
+		}
+		sql = sql.Where(keyset)
 	}
 
 	if q.limit() > 0 {
@@ -37,15 +83,95 @@ func (q SQLQuery) ToSQL() (string, []any, error) {
 		ToSql()
 }
 
+// Returns SQL and arguments for placeholders that count all rows matching the filters of the
+// query, ignoring sorts and paging.
+func (q SQLQuery) ToCountSQL() (string, []any, error) {
+	if err := q.checkSearch(); err != nil {
+		return "", nil, err
+	}
+
+	return q.where(sq.Select("COUNT(*)").From(q.Table)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+}
+
+// Returns SQL and arguments for placeholders that count the rows matching the filters of the
+// query with each value of the field, ignoring sorts and paging. Each row of the result is a value
+// and its count, ordered by descending count then by value.
+func (q SQLQuery) ToGroupSQL(field string) (string, []any, error) {
+	return q.where(sq.Select(field, "COUNT(*)").From(q.Table)).
+		GroupBy(field).
+		OrderBy("COUNT(*) DESC", fmt.Sprintf("%s %s", field, mapDirection(SortAsc))).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+}
+
+// returns the select with a SQL WHERE clause from QuerySpec.
+func (q SQLQuery) where(sql sq.SelectBuilder) sq.SelectBuilder {
+	for _, f := range q.Query.Filters {
+		sql = sql.Where(mapExpr(f))
+	}
+	if q.Query.Search != "" {
+		sql = sql.Where(q.searchExpr("%[1]s @@ %[2]s"))
+	}
+	return sql
+}
+
+// returns an error if the query has a search but the table has no search vector.
+func (q SQLQuery) checkSearch() error {
+	if q.Query.Search != "" && (q.SearchVector == "" || len(q.SearchFields) == 0) {
+		return fmt.Errorf("query with search requires a search vector")
+	}
+	return nil
+}
+
+// returns a Squirrel expression of the search of the query. In the format, %[1]s is the search
+// vector, %[2]s the text search query, %[3]s the text search configuration and %[4]s the search
+// fields.
+func (q SQLQuery) searchExpr(format string) sq.Sqlizer {
+	query := fmt.Sprintf("websearch_to_tsquery('%s', ?)", textSearchConfig)
+	return sq.Expr(fmt.Sprintf(format, q.SearchVector, query, textSearchConfig, strings.Join(q.SearchFields, ", ")), q.Query.Search)
+}
+
 // returns SQL ORDER BY clause from QuerySpec.
 func (q SQLQuery) orderBy() []string {
 	var clause = make([]string, len(q.Query.Sorts))
 	for i, s := range q.Query.Sorts {
 		clause[i] = fmt.Sprintf("%s %s", s.Field, mapDirection(s.Direction))
 	}
+	if q.Query.Search != "" {
+		clause = append(clause, "rank DESC")
+	}
+	if q.KeyField != "" {
+		clause = append(clause, fmt.Sprintf("%s %s", q.KeyField, mapDirection(SortAsc)))
+	}
 	return clause
 }
 
+// returns a Squirrel expression selecting the rows after the cursor in the order of the sorts
+// and key field. Nulls are ordered after all other values, as they are by default in SQL.
+func (q SQLQuery) keyset(cursor collection.Cursor) (sq.Sqlizer, error) {
+	if q.KeyField == "" {
+		return nil, fmt.Errorf("query after cursor requires a key field")
+	}
+	if err := cursor.CheckSorts(q.Query.Sorts); err != nil {
+		return nil, err
+	}
+
+	keyset := sq.Or{}
+	equal := sq.And{}
+	for i, s := range q.Query.Sorts {
+		if after := mapAfter(s, cursor.Values[i]); after != nil {
+			term := append(sq.And{}, equal...)
+			keyset = append(keyset, append(term, after))
+		}
+		equal = append(equal, sq.Eq{s.Field: cursor.Values[i]})
+	}
+	keyset = append(keyset, append(equal, sq.Gt{q.KeyField: cursor.Key}))
+
+	return keyset, nil
+}
+
 // returns SQL limit from QuerySpec.
 func (q SQLQuery) limit() uint64 {
 	return q.Query.Size
@@ -53,15 +179,39 @@ func (q SQLQuery) limit() uint64 {
 
 // returns SQL offset form QuerySpec.
 func (q SQLQuery) offset() uint64 {
-	if q.Query.Size == 0 || q.Query.Page == 0 {
+	if q.Query.Size == 0 || q.Query.Page == 0 || q.Query.After != nil {
 		return 0
 	}
 
 	return (q.Query.Page - 1) * q.Query.Size
 }
 
+// returns a given Squirrel expression for a filter expression
+func mapExpr(expr collection.Expr) sq.Sqlizer {
+	switch e := expr.(type) {
+	case collection.FilterExpr:
+		return mapFilter(e)
+	case collection.AndExpr:
+		and := sq.And{}
+		for _, x := range e {
+			and = append(and, mapExpr(x))
+		}
+		return and
+	case collection.OrExpr:
+		or := sq.Or{}
+		for _, x := range e {
+			or = append(or, mapExpr(x))
+		}
+		return or
+	case collection.NotExpr:
+		return sq.Expr("NOT (?)", mapExpr(e.Expr))
+	default:
+		panic(fmt.Sprintf("unknown query filter expression: %T", expr))
+	}
+}
+
 // returns a given Squirrel expression for a filter
-func mapFilter(filter collection.FilterExpr) any {
+func mapFilter(filter collection.FilterExpr) sq.Sqlizer {
 	switch filter.Operator {
 	case OpEq:
 		return sq.Eq{filter.Field: filter.Value}
@@ -75,11 +225,50 @@ func mapFilter(filter collection.FilterExpr) any {
 		return sq.Gt{filter.Field: filter.Value}
 	case OpGe:
 		return sq.GtOrEq{filter.Field: filter.Value}
+	case OpIn:
+		return sq.Eq{filter.Field: filter.Value}
+	case OpOut:
+		return sq.NotEq{filter.Field: filter.Value}
+	case OpLike:
+		return sq.Like{filter.Field: filter.Value}
+	case OpILike:
+		return sq.ILike{filter.Field: filter.Value}
+	case OpContains:
+		return sq.Like{filter.Field: "%" + escapeLike(fmt.Sprint(filter.Value)) + "%"}
+	case OpStartsWith:
+		return sq.Like{filter.Field: escapeLike(fmt.Sprint(filter.Value)) + "%"}
+	case OpIsNull:
+		if isNull, _ := strconv.ParseBool(fmt.Sprint(filter.Value)); isNull {
+			return sq.Eq{filter.Field: nil}
+		}
+		return sq.NotEq{filter.Field: nil}
 	default:
 		panic(fmt.Sprintf("unknown query filter operator: %s", filter.Operator))
 	}
 }
 
+// returns text with the wildcards of SQL LIKE patterns escaped, so it matches itself.
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// returns a Squirrel expression for the values of a sort field after the given value,
+// or nil if there are none.
+func mapAfter(sort collection.SortExpr, value any) sq.Sqlizer {
+	switch {
+	case mapDirection(sort.Direction) == "ASC" && value == nil:
+		return nil
+	case mapDirection(sort.Direction) == "ASC":
+		return sq.Or{sq.Gt{sort.Field: value}, sq.Eq{sort.Field: nil}}
+	case value == nil:
+		return sq.NotEq{sort.Field: nil}
+	default:
+		return sq.Lt{sort.Field: value}
+	}
+}
+
 // returns a given Squirrel order by direction for a given direction
 func mapDirection(direction collection.Direction) string {
 	switch direction {
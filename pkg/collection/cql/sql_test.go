@@ -21,13 +21,94 @@ func TestReturnOnlySelectFrom(t *testing.T) {
 	assert.Len(t, args, 0)
 }
 
+func TestReturnAllFieldsWhenNoneSelected(t *testing.T) {
+	// When
+	fields := cql.SelectFields(collection.QuerySpec{}, []string{"id", "foo", "bar"}, "id")
+
+	// Then
+	assert.Equal(t, []string{"id", "foo", "bar"}, fields)
+}
+
+func TestReturnSelectedRequiredAndSortFields(t *testing.T) {
+	// Given
+	query := collection.QuerySpec{
+		Fields: []string{"baz"},
+		Sorts:  []collection.SortExpr{{Field: "foo", Direction: cql.SortAsc}},
+	}
+
+	// When
+	fields := cql.SelectFields(query, []string{"id", "foo", "bar", "baz"}, "id")
+
+	// Then
+	assert.Equal(t, []string{"id", "foo", "baz"}, fields)
+}
+
+func TestReturnGroupBy(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{
+		Table: "bar",
+		Query: collection.QuerySpec{
+			Filters: []collection.Expr{collection.FilterExpr{Field: "bam", Operator: cql.OpEq, Value: "baz"}},
+			Sorts:   []collection.SortExpr{{Field: "bam", Direction: cql.SortAsc}},
+			Page:    2,
+			Size:    10,
+		}}
+
+	// When
+	sql, args, err := q.ToGroupSQL("foo")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT foo, COUNT(*) FROM bar WHERE bam = $1 GROUP BY foo ORDER BY COUNT(*) DESC, foo ASC", sql)
+	assert.Equal(t, []any{"baz"}, args)
+}
+
+func TestReturnSearchRankedAfterSorts(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{
+		Fields:       []string{"foo"},
+		Table:        "bar",
+		KeyField:     "id",
+		SearchVector: "search",
+		SearchFields: []string{"foo", "bam"},
+		Query: collection.QuerySpec{
+			Filters: []collection.Expr{collection.FilterExpr{Field: "bam", Operator: cql.OpEq, Value: "baz"}},
+			Sorts:   []collection.SortExpr{{Field: "foo", Direction: cql.SortDesc}},
+			Search:  "quick fox",
+		}}
+
+	// When
+	sql, args, err := q.ToSQL()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT foo, ts_rank(search, websearch_to_tsquery('english', $1)) AS rank, "+
+		"ts_headline('english', replace(replace(replace(replace(replace(concat_ws(' ', foo, bam), "+
+		"'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&#34;'), '''', '&#39;'), "+
+		"websearch_to_tsquery('english', $2)) AS snippet "+
+		"FROM bar WHERE bam = $3 AND search @@ websearch_to_tsquery('english', $4) "+
+		"ORDER BY foo DESC, rank DESC, id ASC", sql)
+	assert.Equal(t, []any{"quick fox", "quick fox", "baz", "quick fox"}, args)
+}
+
+func TestReturnErrorOnSearchWithoutSearchVector(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{Fields: []string{"foo"}, Table: "bar", Query: collection.QuerySpec{Search: "fox"}}
+
+	// When
+	_, _, err := q.ToSQL()
+
+	// Then
+	assert.Error(t, err)
+}
+
 func TestReturnWhereEquals(t *testing.T) {
 	// Given
 	q := cql.SQLQuery{
 		Fields: []string{"foo"},
 		Table:  "bar",
 		Query: collection.QuerySpec{
-			Filters: []collection.FilterExpr{{
+			Filters: []collection.Expr{collection.FilterExpr{
 				Field:    "bam",
 				Operator: cql.OpEq,
 				Value:    "baz",
@@ -49,7 +130,7 @@ func TestReturnWhereNotEquals(t *testing.T) {
 		Fields: []string{"foo"},
 		Table:  "bar",
 		Query: collection.QuerySpec{
-			Filters: []collection.FilterExpr{{
+			Filters: []collection.Expr{collection.FilterExpr{
 				Field:    "bam",
 				Operator: cql.OpNe,
 				Value:    "baz",
@@ -142,3 +223,164 @@ func TestReturnAllWhenPageWithNoSize(t *testing.T) {
 	assert.Equal(t, "SELECT foo FROM bar", sql)
 	assert.Len(t, args, 0)
 }
+
+func TestReturnCountIgnoringSortAndPage(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{
+		Table:  "bar",
+		Fields: []string{"foo"},
+		Query: collection.QuerySpec{
+			Filters: []collection.Expr{collection.FilterExpr{Field: "bam", Operator: cql.OpEq, Value: "baz"}},
+			Sorts:   []collection.SortExpr{{Field: "foo", Direction: cql.SortAsc}},
+			Size:    10,
+			Page:    2,
+		},
+	}
+
+	// When
+	sql, args, err := q.ToCountSQL()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM bar WHERE bam = $1", sql)
+	assert.Equal(t, []any{"baz"}, args)
+}
+
+func TestReturnOrderByKeyField(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{
+		Table:    "bar",
+		Fields:   []string{"foo"},
+		KeyField: "id",
+		Query: collection.QuerySpec{
+			Sorts: []collection.SortExpr{{Field: "foo", Direction: cql.SortDesc}},
+		},
+	}
+
+	// When
+	sql, _, err := q.ToSQL()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT foo FROM bar ORDER BY foo DESC, id ASC", sql)
+}
+
+func TestReturnKeysetAfterCursor(t *testing.T) {
+	// Given
+	sorts := []collection.SortExpr{{Field: "foo", Direction: cql.SortAsc}, {Field: "bam", Direction: cql.SortDesc}}
+	q := cql.SQLQuery{
+		Table:    "bar",
+		Fields:   []string{"foo"},
+		KeyField: "id",
+		Query: collection.QuerySpec{
+			Sorts: sorts,
+			Page:  3,
+			Size:  10,
+			After: &collection.Cursor{Sorts: sorts, Values: []any{"a", "b"}, Key: "k"},
+		},
+	}
+
+	// When
+	sql, args, err := q.ToSQL()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT foo FROM bar "+
+		"WHERE (((foo > $1 OR foo IS NULL)) OR (foo = $2 AND bam < $3) OR (foo = $4 AND bam = $5 AND id > $6)) "+
+		"ORDER BY foo ASC, bam DESC, id ASC LIMIT 10", sql)
+	assert.Equal(t, []any{"a", "a", "b", "a", "b", "k"}, args)
+}
+
+func TestReturnKeysetAfterCursorWithNulls(t *testing.T) {
+	// Given
+	sorts := []collection.SortExpr{{Field: "foo", Direction: cql.SortAsc}, {Field: "bam", Direction: cql.SortDesc}}
+	q := cql.SQLQuery{
+		Table:    "bar",
+		Fields:   []string{"foo"},
+		KeyField: "id",
+		Query: collection.QuerySpec{
+			Sorts: sorts,
+			After: &collection.Cursor{Sorts: sorts, Values: []any{nil, nil}, Key: "k"},
+		},
+	}
+
+	// When
+	sql, args, err := q.ToSQL()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT foo FROM bar "+
+		"WHERE ((foo IS NULL AND bam IS NOT NULL) OR (foo IS NULL AND bam IS NULL AND id > $1)) "+
+		"ORDER BY foo ASC, bam DESC, id ASC", sql)
+	assert.Equal(t, []any{"k"}, args)
+}
+
+func TestReturnErrorOnCursorFromOtherSorts(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{
+		Table:    "bar",
+		Fields:   []string{"foo"},
+		KeyField: "id",
+		Query: collection.QuerySpec{
+			Sorts: []collection.SortExpr{{Field: "foo", Direction: cql.SortAsc}},
+			After: &collection.Cursor{Key: "k"},
+		},
+	}
+
+	// When
+	_, _, err := q.ToSQL()
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+}
+
+func TestReturnWhereNestedBooleanExpressions(t *testing.T) {
+	// Given
+	q := cql.SQLQuery{
+		Fields: []string{"foo"},
+		Table:  "bar",
+		Query: collection.QuerySpec{
+			Filters: []collection.Expr{collection.OrExpr{
+				collection.FilterExpr{Field: "a", Operator: cql.OpEq, Value: "1"},
+				collection.AndExpr{
+					collection.FilterExpr{Field: "b", Operator: cql.OpGt, Value: "2"},
+					collection.NotExpr{Expr: collection.FilterExpr{Field: "c", Operator: cql.OpNe, Value: "3"}},
+				},
+			}}}}
+
+	// When
+	sql, args, err := q.ToSQL()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT foo FROM bar WHERE (a = $1 OR (b > $2 AND NOT (c <> $3)))", sql)
+	assert.Equal(t, []any{"1", "2", "3"}, args)
+}
+
+func TestReturnWhereSetPatternAndNullOperators(t *testing.T) {
+	for _, tc := range []struct {
+		filter collection.FilterExpr
+		sql    string
+		args   []any
+	}{
+		{collection.FilterExpr{Field: "a", Operator: cql.OpIn, Value: []string{"1", "2"}}, "a IN ($1,$2)", []any{"1", "2"}},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpOut, Value: []string{"1"}}, "a NOT IN ($1)", []any{"1"}},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpLike, Value: `f\%o%`}, "a LIKE $1", []any{`f\%o%`}},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpILike, Value: "f%"}, "a ILIKE $1", []any{"f%"}},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpContains, Value: `5%_\`}, "a LIKE $1", []any{`%5\%\_\\%`}},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpStartsWith, Value: "f_"}, "a LIKE $1", []any{`f\_%`}},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpIsNull, Value: true}, "a IS NULL", nil},
+		{collection.FilterExpr{Field: "a", Operator: cql.OpIsNull, Value: false}, "a IS NOT NULL", nil},
+	} {
+		// Given
+		q := cql.SQLQuery{Fields: []string{"foo"}, Table: "bar", Query: collection.QuerySpec{Filters: []collection.Expr{tc.filter}}}
+
+		// When
+		sql, args, err := q.ToSQL()
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT foo FROM bar WHERE "+tc.sql, sql, tc.filter.Operator)
+		assert.Equal(t, tc.args, args, tc.filter.Operator)
+	}
+}
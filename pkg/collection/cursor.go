@@ -0,0 +1,85 @@
+package collection
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Cursor describes the position of a resource in a sorted collection, so that a query can
+// continue with the resources after it.
+type Cursor struct {
+
+	// Sorts are the sorts of the query that returned the resource.
+	Sorts []SortExpr `json:"s"`
+
+	// Values are the values of the sort fields of the resource, in the order of Sorts.
+	Values []any `json:"v"`
+
+	// Key is the unique key of the resource, which orders resources with equal sort values.
+	Key string `json:"k"`
+}
+
+// Returns a QueryError if the cursor was not returned by a query with the given sorts.
+func (c Cursor) CheckSorts(sorts []SortExpr) error {
+	if !slices.Equal(c.Sorts, sorts) || len(c.Values) != len(sorts) {
+		return QueryError{Message: "invalid cursor: sort does not match query"}
+	}
+	return nil
+}
+
+// CursorCodec encodes cursors as opaque strings signed with a secret key, so that clients
+// cannot read or forge them.
+type CursorCodec struct {
+	Key []byte
+}
+
+// Encode returns the cursor as a signed opaque string.
+func (cc CursorCodec) Encode(cursor Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("encoding cursor failed: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(cc.sign(payload)), nil
+}
+
+// Decode returns the cursor in a string returned by Encode.
+// Returns QueryError if the string is malformed or was not signed with the codec's key.
+func (cc CursorCodec) Decode(s string) (Cursor, error) {
+	invalid := QueryError{Message: "invalid cursor"}
+	encoding := base64.RawURLEncoding
+
+	p, sig, ok := strings.Cut(s, ".")
+	if !ok {
+		return Cursor{}, invalid
+	}
+	payload, err := encoding.DecodeString(p)
+	if err != nil {
+		return Cursor{}, invalid
+	}
+	signature, err := encoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(signature, cc.sign(payload)) {
+		return Cursor{}, invalid
+	}
+
+	cursor := Cursor{}
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return Cursor{}, invalid
+	}
+
+	return cursor, nil
+}
+
+// returns the HMAC-SHA256 of the payload.
+func (cc CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, cc.Key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
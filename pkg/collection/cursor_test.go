@@ -0,0 +1,67 @@
+package collection_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+func TestShouldDecodeEncodedCursor(t *testing.T) {
+	// Given
+	codec := collection.CursorCodec{Key: []byte("secret")}
+	cursor := collection.Cursor{
+		Sorts:  []collection.SortExpr{{Field: "foo", Direction: "asc"}},
+		Values: []any{"bar"},
+		Key:    "1",
+	}
+
+	// When
+	encoded, err := codec.Encode(cursor)
+	require.NoError(t, err)
+	decoded, err := codec.Decode(encoded)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestShouldRejectCursorSignedWithOtherKey(t *testing.T) {
+	// Given
+	encoded, err := collection.CursorCodec{Key: []byte("other")}.Encode(collection.Cursor{Key: "1"})
+	require.NoError(t, err)
+
+	// When
+	_, err = collection.CursorCodec{Key: []byte("secret")}.Decode(encoded)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+}
+
+func TestShouldRejectMalformedCursor(t *testing.T) {
+	// Given
+	codec := collection.CursorCodec{Key: []byte("secret")}
+
+	// When
+	_, err := codec.Decode("foo")
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+}
+
+func TestShouldRejectCursorFromOtherSorts(t *testing.T) {
+	// Given
+	cursor := collection.Cursor{
+		Sorts:  []collection.SortExpr{{Field: "foo", Direction: "asc"}},
+		Values: []any{"bar"},
+		Key:    "1",
+	}
+
+	// When
+	err := cursor.CheckSorts([]collection.SortExpr{{Field: "foo", Direction: "desc"}})
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+}
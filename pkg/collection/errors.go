@@ -3,6 +3,10 @@ package collection
 // QueryError is returned when a query is invalid.
 type QueryError struct {
 	Message string
+
+	// Position is the position in the query parameter of a syntax error, counting from 1.
+	// 0 is not set.
+	Position int
 }
 
 func (qe QueryError) Error() string {
@@ -0,0 +1,52 @@
+package collection
+
+import (
+	"fmt"
+)
+
+// FacetSpec describes a query for the number of resources in a collection with each value of
+// one or more fields.
+type FacetSpec struct {
+	Filters []Expr   // resources must match every filter
+	By      []string // fields to count the values of, at least one is required
+}
+
+// Facets describes the number of matching resources with each value of each field of a FacetSpec.
+type Facets struct {
+	Facets []Facet `json:"facets"`
+}
+
+// Facet describes the number of matching resources with each value of a field.
+type Facet struct {
+	Field string `json:"field"`
+
+	// Buckets are ordered by descending count, then by value with null last.
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Bucket describes the number of matching resources with a value of a field.
+type Bucket struct {
+	Value any    `json:"value"`
+	Count uint64 `json:"count"`
+}
+
+// Validates the facet query against a set of field capabilities, and converts the values of its
+// filters to the types of their fields.
+func (f *FacetSpec) Validate(fieldCapabilities map[string]FieldCapability) error {
+	filters, err := ValidateFilters(f.Filters, fieldCapabilities)
+	if err != nil {
+		return err
+	}
+
+	if len(f.By) == 0 {
+		return QueryError{Message: "invalid facets: no fields to count by"}
+	}
+	for _, field := range f.By {
+		if c, ok := fieldCapabilities[field]; !ok || !c.Group {
+			return QueryError{Message: fmt.Sprintf("invalid facet field: %s", field)}
+		}
+	}
+
+	f.Filters = filters
+	return nil
+}
@@ -3,15 +3,26 @@ package collection
 import (
 	"fmt"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
 // QuerySpec describes a common pattern for querying RESTful resource collections.
 type QuerySpec struct {
-	Filters []FilterExpr
+	Filters []Expr // resources must match every filter
 	Sorts   []SortExpr
-	Page    uint64 // 0 is not set
-	Size    uint64 // 0 is not set
+	Page    uint64   // 0 is not set
+	Size    uint64   // 0 is not set
+	Count   bool     // true if the total number of matching resources is required
+	After   *Cursor  // nil is not set, otherwise resources after the cursor replace the page
+	Fields  []string // nil is all fields, otherwise only these fields of each resource are required
+	Search  string   // "" is not set, otherwise resources must match the words and are ranked by relevance
+}
+
+// Expr describes a boolean expression of criteria for matching resources in a collection.
+// An Expr is one of FilterExpr, AndExpr, OrExpr or NotExpr.
+type Expr interface {
+	isExpr()
 }
 
 // FilterExpr describes a criteria for matching resources in a collection.
@@ -21,6 +32,22 @@ type FilterExpr struct {
 	Value    any
 }
 
+// AndExpr matches resources matching all of its expressions.
+type AndExpr []Expr
+
+// OrExpr matches resources matching any of its expressions.
+type OrExpr []Expr
+
+// NotExpr matches resources not matching its expression.
+type NotExpr struct {
+	Expr Expr
+}
+
+func (FilterExpr) isExpr() {}
+func (AndExpr) isExpr()    {}
+func (OrExpr) isExpr()     {}
+func (NotExpr) isExpr()    {}
+
 // Operator describes a mathemtical comparison to be performed with 1 or more values .
 type Operator string
 
@@ -33,10 +60,46 @@ type SortExpr struct {
 type Direction string
 
 // Page describes a subset of query results from a collection of resources.
+// Total and Pages are only present if a count was requested by the query.
 type Page[T any] struct {
-	Results []T    `json:"results"`
-	Page    uint64 `json:"page"`
-	Size    uint64 `json:"size"`
+	Results []T     `json:"results"`
+	Page    uint64  `json:"page"`
+	Size    uint64  `json:"size"`
+	Total   *uint64 `json:"total,omitempty"`
+	Pages   *uint64 `json:"pages,omitempty"`
+
+	// Next is the position of the last result, if the page is full and there may be more results.
+	Next *Cursor `json:"-"`
+
+	// NextCursor is Next as an opaque string, for clients to continue the query.
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Matches describes how each result matched the search of the query, in the order of Results.
+	// nil if the query has no search.
+	Matches []*Match `json:"-"`
+}
+
+// WithTotal returns the page with the total number of matching resources and the number of
+// pages of the given page size needed to hold them. A page size of 0 means all resources are
+// in a single page.
+func (p Page[T]) WithTotal(total uint64, pageSize uint64) Page[T] {
+	pages := total
+	switch {
+	case pageSize == 0 && total > 0:
+		pages = 1
+	case pageSize > 0:
+		pages = (total + pageSize - 1) / pageSize
+	}
+
+	p.Total = &total
+	p.Pages = &pages
+	return p
+}
+
+// Match describes how a resource matched the search of a query.
+type Match struct {
+	Rank    float64 `json:"rank"`    // relevance of the resource, higher ranks match better
+	Snippet string  `json:"snippet"` // the searched text escaped for HTML with matching words enclosed by <b> and </b>
 }
 
 // FieldCapability describes a field of a resource and how it may be used in a collection
@@ -52,17 +115,30 @@ type FieldCapability struct {
 
 	// Sort describes whether the field can be used in a sort expression.
 	Sort bool
+
+	// Select describes whether the field can be selected by the fields of a query.
+	Select bool
+
+	// Group describes whether the matching resources can be counted by the values of the field.
+	Group bool
+
+	// Search describes whether the field is searched by the search of a query.
+	Search bool
+
+	// Type describes the type of the field. Filter values are converted to the type when the
+	// query is validated. "" is TypeString.
+	Type ValueType
+
+	// Values lists the valid values of a field of TypeEnum.
+	Values []string
 }
 
-// Validates the query against a set of field capabilities.
-func (q QuerySpec) Validate(fieldCapabilities map[string]FieldCapability) error {
-	for _, filter := range q.Filters {
-		if f, ok := fieldCapabilities[filter.Field]; !ok || !f.Filter {
-			return QueryError{Message: fmt.Sprintf("invalid filter field: %s", filter.Field)}
-		}
-		if !slices.Contains(fieldCapabilities[filter.Field].FilterOps, filter.Operator) {
-			return QueryError{Message: fmt.Sprintf("invalid filter operator: %s", filter.Operator)}
-		}
+// Validates the query against a set of field capabilities, and converts the values of its
+// filters to the types of their fields.
+func (q *QuerySpec) Validate(fieldCapabilities map[string]FieldCapability) error {
+	filters, err := ValidateFilters(q.Filters, fieldCapabilities)
+	if err != nil {
+		return err
 	}
 
 	for _, sort := range q.Sorts {
@@ -71,5 +147,77 @@ func (q QuerySpec) Validate(fieldCapabilities map[string]FieldCapability) error
 		}
 	}
 
+	if q.Search != "" && q.After != nil {
+		return QueryError{Message: "invalid query: search and cursor are exclusive"}
+	}
+	if q.Search != "" && !slices.ContainsFunc(maps.Values(fieldCapabilities), func(f FieldCapability) bool { return f.Search }) {
+		return QueryError{Message: "invalid search: no fields can be searched"}
+	}
+
+	for _, field := range q.Fields {
+		if f, ok := fieldCapabilities[field]; !ok || !f.Select {
+			return QueryError{Message: fmt.Sprintf("invalid select field: %s", field)}
+		}
+	}
+
+	q.Filters = filters
 	return nil
 }
+
+// ValidateFilters validates filters against a set of field capabilities.
+// Returns the filters with their values converted to the types of their fields, or QueryError.
+func ValidateFilters(filters []Expr, fieldCapabilities map[string]FieldCapability) ([]Expr, error) {
+	validated := make([]Expr, len(filters))
+	for i, filter := range filters {
+		f, err := validateExpr(filter, fieldCapabilities)
+		if err != nil {
+			return nil, err
+		}
+		validated[i] = f
+	}
+	return validated, nil
+}
+
+// Validates every filter of an expression against a set of field capabilities.
+// Returns the expression with the values of its filters converted to the types of their fields.
+func validateExpr(expr Expr, fieldCapabilities map[string]FieldCapability) (Expr, error) {
+	switch e := expr.(type) {
+	case FilterExpr:
+		f, ok := fieldCapabilities[e.Field]
+		if !ok || !f.Filter {
+			return nil, QueryError{Message: fmt.Sprintf("invalid filter field: %s", e.Field)}
+		}
+		if !slices.Contains(f.FilterOps, e.Operator) {
+			return nil, QueryError{Message: fmt.Sprintf("invalid filter operator: %s", e.Operator)}
+		}
+		return f.convertFilter(e)
+	case AndExpr:
+		and := AndExpr{}
+		for _, x := range e {
+			v, err := validateExpr(x, fieldCapabilities)
+			if err != nil {
+				return nil, err
+			}
+			and = append(and, v)
+		}
+		return and, nil
+	case OrExpr:
+		or := OrExpr{}
+		for _, x := range e {
+			v, err := validateExpr(x, fieldCapabilities)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, v)
+		}
+		return or, nil
+	case NotExpr:
+		v, err := validateExpr(e.Expr, fieldCapabilities)
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Expr: v}, nil
+	default:
+		return nil, QueryError{Message: fmt.Sprintf("invalid filter: %v", expr)}
+	}
+}
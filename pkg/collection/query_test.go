@@ -0,0 +1,208 @@
+package collection_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+)
+
+var capabilities = map[string]collection.FieldCapability{
+	"foo": {Filter: true, FilterOps: []collection.Operator{"=="}},
+}
+
+var typedCapabilities = map[string]collection.FieldCapability{
+	"count":   {Filter: true, FilterOps: []collection.Operator{"==", "=in="}, Type: collection.TypeInt},
+	"price":   {Filter: true, FilterOps: []collection.Operator{"=="}, Type: collection.TypeDecimal},
+	"flag":    {Filter: true, FilterOps: []collection.Operator{"=="}, Type: collection.TypeBool},
+	"at":      {Filter: true, FilterOps: []collection.Operator{"=="}, Type: collection.TypeTime},
+	"id":      {Filter: true, FilterOps: []collection.Operator{"=="}, Type: collection.TypeUUID},
+	"status":  {Filter: true, FilterOps: []collection.Operator{"=="}, Type: collection.TypeEnum, Values: []string{"open", "closed"}},
+	"missing": {Filter: true, FilterOps: []collection.Operator{"=isnull="}, Type: collection.TypeInt},
+}
+
+func TestShouldValidateNestedFilters(t *testing.T) {
+	// Given
+	query := collection.QuerySpec{Filters: []collection.Expr{collection.OrExpr{
+		collection.FilterExpr{Field: "foo", Operator: "==", Value: "1"},
+		collection.NotExpr{Expr: collection.AndExpr{collection.FilterExpr{Field: "foo", Operator: "==", Value: "2"}}},
+	}}}
+
+	// When
+	err := query.Validate(capabilities)
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldReturnErrorOnInvalidNestedFilter(t *testing.T) {
+	// Given
+	query := collection.QuerySpec{Filters: []collection.Expr{collection.OrExpr{
+		collection.FilterExpr{Field: "foo", Operator: "==", Value: "1"},
+		collection.NotExpr{Expr: collection.FilterExpr{Field: "bar", Operator: "==", Value: "2"}},
+	}}}
+
+	// When
+	err := query.Validate(capabilities)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+	assert.Contains(t, err.Error(), "bar")
+}
+
+func TestShouldConvertFilterValuesToFieldTypes(t *testing.T) {
+	// Given
+	query := collection.QuerySpec{Filters: []collection.Expr{
+		collection.FilterExpr{Field: "count", Operator: "==", Value: "42"},
+		collection.FilterExpr{Field: "price", Operator: "==", Value: "1.5"},
+		collection.FilterExpr{Field: "flag", Operator: "==", Value: "true"},
+		collection.FilterExpr{Field: "at", Operator: "==", Value: "2023-01-02T03:04:05Z"},
+		collection.FilterExpr{Field: "id", Operator: "==", Value: "A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11"},
+		collection.FilterExpr{Field: "status", Operator: "==", Value: "open"},
+		collection.NotExpr{Expr: collection.FilterExpr{Field: "count", Operator: "=in=", Value: []string{"1", "2"}}},
+		collection.FilterExpr{Field: "missing", Operator: "=isnull=", Value: true},
+	}}
+
+	// When
+	err := query.Validate(typedCapabilities)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []collection.Expr{
+		collection.FilterExpr{Field: "count", Operator: "==", Value: int64(42)},
+		collection.FilterExpr{Field: "price", Operator: "==", Value: 1.5},
+		collection.FilterExpr{Field: "flag", Operator: "==", Value: true},
+		collection.FilterExpr{Field: "at", Operator: "==", Value: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)},
+		collection.FilterExpr{Field: "id", Operator: "==", Value: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		collection.FilterExpr{Field: "status", Operator: "==", Value: "open"},
+		collection.NotExpr{Expr: collection.FilterExpr{Field: "count", Operator: "=in=", Value: []any{int64(1), int64(2)}}},
+		collection.FilterExpr{Field: "missing", Operator: "=isnull=", Value: true},
+	}, query.Filters)
+}
+
+func TestShouldConvertRelativeTimeFilterValues(t *testing.T) {
+	// Given
+	query := collection.QuerySpec{Filters: []collection.Expr{
+		collection.FilterExpr{Field: "at", Operator: "==", Value: "now"},
+		collection.FilterExpr{Field: "at", Operator: "==", Value: "now-24h"},
+		collection.FilterExpr{Field: "at", Operator: "==", Value: "now+1h30m"},
+	}}
+	before := time.Now()
+
+	// When
+	err := query.Validate(typedCapabilities)
+
+	// Then
+	after := time.Now()
+	require.NoError(t, err)
+	for i, offset := range []time.Duration{0, -24 * time.Hour, 90 * time.Minute} {
+		value, ok := query.Filters[i].(collection.FilterExpr).Value.(time.Time)
+		require.True(t, ok, offset)
+		assert.WithinRange(t, value, before.Add(offset), after.Add(offset), offset)
+	}
+}
+
+func TestShouldReturnErrorOnInvalidFilterValue(t *testing.T) {
+	tests := []struct {
+		field string
+		value any
+	}{
+		{field: "count", value: "1.5"},
+		{field: "count", value: []string{"1", "x"}},
+		{field: "price", value: "cheap"},
+		{field: "flag", value: "maybe"},
+		{field: "at", value: "2023-01-02"},
+		{field: "at", value: "now-1d"},
+		{field: "at", value: "nowish"},
+		{field: "id", value: "123"},
+		{field: "status", value: "pending"},
+	}
+
+	for _, test := range tests {
+		// Given
+		operator := collection.Operator("==")
+		if _, ok := test.value.([]string); ok {
+			operator = "=in="
+		}
+		query := collection.QuerySpec{Filters: []collection.Expr{
+			collection.FilterExpr{Field: test.field, Operator: operator, Value: test.value},
+		}}
+
+		// When
+		err := query.Validate(typedCapabilities)
+
+		// Then
+		assert.ErrorAs(t, err, &collection.QueryError{}, "%s %v", test.field, test.value)
+	}
+}
+
+func TestShouldReturnErrorOnFieldNotSelectable(t *testing.T) {
+	// Given
+	caps := map[string]collection.FieldCapability{"foo": {Select: true}, "bar": {Filter: true}}
+	query := collection.QuerySpec{Fields: []string{"foo", "bar"}}
+
+	// When
+	err := query.Validate(caps)
+
+	// Then
+	assert.ErrorAs(t, err, &collection.QueryError{})
+	assert.Contains(t, err.Error(), "bar")
+}
+
+func TestShouldValidateFacets(t *testing.T) {
+	// Given
+	caps := map[string]collection.FieldCapability{"foo": {Group: true}, "count": typedCapabilities["count"]}
+	facets := collection.FacetSpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: "count", Operator: "==", Value: "1"}},
+		By:      []string{"foo"},
+	}
+
+	// When
+	err := facets.Validate(caps)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), facets.Filters[0].(collection.FilterExpr).Value)
+}
+
+func TestShouldReturnErrorOnInvalidFacets(t *testing.T) {
+	caps := map[string]collection.FieldCapability{"foo": {Group: true}, "bar": {Sort: true}}
+
+	for _, by := range [][]string{nil, {"bar"}, {"foo", "baz"}} {
+		// Given
+		facets := collection.FacetSpec{By: by}
+
+		// When
+		err := facets.Validate(caps)
+
+		// Then
+		assert.ErrorAs(t, err, &collection.QueryError{}, by)
+	}
+}
+
+func TestShouldReturnErrorOnInvalidSearch(t *testing.T) {
+	caps := map[string]collection.FieldCapability{"foo": {Search: true}}
+
+	for _, test := range []struct {
+		query collection.QuerySpec
+		caps  map[string]collection.FieldCapability
+	}{
+		{query: collection.QuerySpec{Search: "foo"}, caps: capabilities},
+		{query: collection.QuerySpec{Search: "foo", After: &collection.Cursor{}}, caps: caps},
+	} {
+		// When
+		err := test.query.Validate(test.caps)
+
+		// Then
+		assert.ErrorAs(t, err, &collection.QueryError{})
+	}
+
+	// When
+	err := (&collection.QuerySpec{Search: "foo"}).Validate(caps)
+
+	// Then
+	assert.NoError(t, err)
+}
@@ -0,0 +1,332 @@
+// Package rsql parses collection query filters written in RSQL, a query language based on FIQL,
+// e.g. status==open;priority=gt=2,assignee==me. Filters are parsed to the expressions and
+// operators of package cql, so they are validated and evaluated the same as cql filters.
+package rsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+)
+
+// ParamQuery is the URL query parameter of an RSQL filter.
+const ParamQuery string = "q"
+
+// A filter is parsed by the grammar:
+//
+//	filter     = or
+//	or         = and { "," and }
+//	and        = constraint { ";" constraint }
+//	constraint = "(" or ")" | comparison
+//	comparison = field operator value | field set-operator "(" value { "," value } ")"
+//
+// The set operators are =in= and =out=. The value of =isnull= must be true or false. A value
+// containing white space or one of "'();,=!~<> must be quoted with double or single quotes, and
+// the quote or a backslash in a quoted value escaped by a backslash. White space between tokens
+// is ignored.
+
+// operators maps RSQL operators to cql operators, in the order they are matched, so longer
+// operators are matched before their prefixes.
+var operators = []struct {
+	token    string
+	operator collection.Operator
+}{
+	{"==", cql.OpEq},
+	{"!=", cql.OpNe},
+	{"=lt=", cql.OpLt},
+	{"=le=", cql.OpLe},
+	{"=gt=", cql.OpGt},
+	{"=ge=", cql.OpGe},
+	{"<=", cql.OpLe},
+	{">=", cql.OpGe},
+	{"<", cql.OpLt},
+	{">", cql.OpGt},
+	{"=in=", cql.OpIn},
+	{"=out=", cql.OpOut},
+	{"=like=", cql.OpLike},
+	{"=ilike=", cql.OpILike},
+	{"=contains=", cql.OpContains},
+	{"=startswith=", cql.OpStartsWith},
+	{"=isnull=", cql.OpIsNull},
+}
+
+// reserved are the characters that end an unquoted value.
+const reserved = `"'();,=!~<>`
+
+// Parse returns the expression of an RSQL filter.
+// Returns QueryError with the position of the first syntax error in the filter.
+func Parse(filter string) (collection.Expr, error) {
+	p := parser{input: filter}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.unexpected()
+	}
+
+	return expr, nil
+}
+
+// ParseFilters returns the expressions of RSQL filters.
+// Returns QueryError with the position of the first syntax error in a filter.
+func ParseFilters(filters []string) ([]collection.Expr, error) {
+	exprs := []collection.Expr{}
+
+	for _, f := range filters {
+		expr, err := Parse(f)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	return exprs, nil
+}
+
+// parser is a recursive descent parser of a single RSQL filter.
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) parseOr() (collection.Expr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	or := collection.OrExpr{expr}
+	for p.skipSpace(); p.consume(","); p.skipSpace() {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd() (collection.Expr, error) {
+	expr, err := p.parseConstraint()
+	if err != nil {
+		return nil, err
+	}
+
+	and := collection.AndExpr{expr}
+	for p.skipSpace(); p.consume(";"); p.skipSpace() {
+		expr, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *parser) parseConstraint() (collection.Expr, error) {
+	p.skipSpace()
+	if !p.consume("(") {
+		return p.parseComparison()
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.consume(")") {
+		return nil, p.expected(`")"`)
+	}
+
+	return expr, nil
+}
+
+func (p *parser) parseComparison() (collection.Expr, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isFieldByte(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, p.expected("field")
+	}
+	field := p.input[start:p.pos]
+
+	p.skipSpace()
+	operator, ok := p.parseOperator()
+	if !ok {
+		return nil, p.expected("operator")
+	}
+
+	p.skipSpace()
+	var value any
+	var err error
+	switch operator {
+	case cql.OpIn, cql.OpOut:
+		value, err = p.parseList()
+	case cql.OpIsNull:
+		value, err = p.parseBool()
+	default:
+		value, err = p.parseValue()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return collection.FilterExpr{Field: field, Operator: operator, Value: value}, nil
+}
+
+func (p *parser) parseOperator() (collection.Operator, bool) {
+	for _, op := range operators {
+		if p.consume(op.token) {
+			return op.operator, true
+		}
+	}
+	return "", false
+}
+
+// parseValue returns a quoted or unquoted value.
+func (p *parser) parseValue() (string, error) {
+	if p.pos < len(p.input) && (p.input[p.pos] == '"' || p.input[p.pos] == '\'') {
+		quote := p.input[p.pos]
+		p.pos++
+		return p.parseQuoted(quote)
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || strings.ContainsRune(reserved, r) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.expected("value")
+	}
+
+	return p.input[start:p.pos], nil
+}
+
+// parseList returns the values of a parenthesized list of one or more values.
+func (p *parser) parseList() ([]string, error) {
+	if !p.consume("(") {
+		return nil, p.expected(`"("`)
+	}
+
+	values := []string{}
+	for {
+		p.skipSpace()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipSpace()
+		if p.consume(")") {
+			return values, nil
+		}
+		if !p.consume(",") {
+			return nil, p.expected(`"," or ")"`)
+		}
+	}
+}
+
+// parseBool returns the value of true or false.
+func (p *parser) parseBool() (bool, error) {
+	start := p.pos
+	value, err := p.parseValue()
+	if err != nil {
+		return false, err
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.pos = start
+		return false, p.expected("true or false")
+	}
+
+	return b, nil
+}
+
+// parseQuoted returns the value of a quoted string after its opening quote.
+func (p *parser) parseQuoted(quote byte) (string, error) {
+	var value strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return value.String(), nil
+		case c == '\\' && p.pos < len(p.input):
+			value.WriteByte(p.input[p.pos])
+			p.pos++
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return "", p.expected(fmt.Sprintf("%q", string(quote)))
+}
+
+// consume consumes the token if it is next in the input.
+func (p *parser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+func (p *parser) expected(what string) error {
+	if p.pos >= len(p.input) {
+		return p.errorf("expected %s but found end", what)
+	}
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return p.errorf("expected %s but found %q", what, r)
+}
+
+func (p *parser) unexpected() error {
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return p.errorf("unexpected %q", r)
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return collection.QueryError{
+		Message:  fmt.Sprintf("invalid query: %s: %s at position %d", p.input, fmt.Sprintf(format, args...), p.pos+1),
+		Position: p.pos + 1,
+	}
+}
+
+// isFieldByte returns true if c may be part of a field name.
+func isFieldByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,161 @@
+package rsql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/collection/rsql"
+)
+
+func TestShouldReturnComparison(t *testing.T) {
+	// When
+	result, err := rsql.Parse("status==open")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, collection.FilterExpr{Field: "status", Operator: cql.OpEq, Value: "open"}, result)
+}
+
+func TestShouldReturnCqlOperators(t *testing.T) {
+	for filter, operator := range map[string]collection.Operator{
+		"a==1":           cql.OpEq,
+		"a!=1":           cql.OpNe,
+		"a=lt=1":         cql.OpLt,
+		"a<1":            cql.OpLt,
+		"a=le=1":         cql.OpLe,
+		"a<=1":           cql.OpLe,
+		"a=gt=1":         cql.OpGt,
+		"a>1":            cql.OpGt,
+		"a=ge=1":         cql.OpGe,
+		"a>=1":           cql.OpGe,
+		"a=like=1":       cql.OpLike,
+		"a=ilike=1":      cql.OpILike,
+		"a=contains=1":   cql.OpContains,
+		"a=startswith=1": cql.OpStartsWith,
+	} {
+		// When
+		result, err := rsql.Parse(filter)
+
+		// Then
+		require.NoError(t, err, filter)
+		assert.Equal(t, collection.FilterExpr{Field: "a", Operator: operator, Value: "1"}, result, filter)
+	}
+}
+
+func TestShouldReturnAndBeforeOr(t *testing.T) {
+	// When
+	result, err := rsql.Parse("status==open;priority=gt=2,assignee==me")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, collection.OrExpr{
+		collection.AndExpr{
+			collection.FilterExpr{Field: "status", Operator: cql.OpEq, Value: "open"},
+			collection.FilterExpr{Field: "priority", Operator: cql.OpGt, Value: "2"},
+		},
+		collection.FilterExpr{Field: "assignee", Operator: cql.OpEq, Value: "me"},
+	}, result)
+}
+
+func TestShouldReturnGroup(t *testing.T) {
+	// When
+	result, err := rsql.Parse("status==open; (priority=gt=2 , assignee==me)")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, collection.AndExpr{
+		collection.FilterExpr{Field: "status", Operator: cql.OpEq, Value: "open"},
+		collection.OrExpr{
+			collection.FilterExpr{Field: "priority", Operator: cql.OpGt, Value: "2"},
+			collection.FilterExpr{Field: "assignee", Operator: cql.OpEq, Value: "me"},
+		},
+	}, result)
+}
+
+func TestShouldReturnQuotedValues(t *testing.T) {
+	// When
+	result, err := rsql.Parse(`a=="x;y",b=='it\'s (1)'`)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, collection.OrExpr{
+		collection.FilterExpr{Field: "a", Operator: cql.OpEq, Value: "x;y"},
+		collection.FilterExpr{Field: "b", Operator: cql.OpEq, Value: "it's (1)"},
+	}, result)
+}
+
+func TestShouldReturnListAndNull(t *testing.T) {
+	// When
+	result, err := rsql.Parse("a=in=(1,'2,3');b=out=(4);c=isnull=false")
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, collection.AndExpr{
+		collection.FilterExpr{Field: "a", Operator: cql.OpIn, Value: []string{"1", "2,3"}},
+		collection.FilterExpr{Field: "b", Operator: cql.OpOut, Value: []string{"4"}},
+		collection.FilterExpr{Field: "c", Operator: cql.OpIsNull, Value: false},
+	}, result)
+}
+
+func TestShouldReturnFilters(t *testing.T) {
+	// When
+	result, err := rsql.ParseFilters([]string{"a==1", "b==2"})
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []collection.Expr{
+		collection.FilterExpr{Field: "a", Operator: cql.OpEq, Value: "1"},
+		collection.FilterExpr{Field: "b", Operator: cql.OpEq, Value: "2"},
+	}, result)
+}
+
+func TestShouldReturnPositionOfError(t *testing.T) {
+	for filter, position := range map[string]int{
+		"":             1,
+		"a==1;":        6,
+		"(a==1":        6,
+		"a==1)":        5,
+		"a==1 b==2":    6,
+		"a=1":          2,
+		"a==":          4,
+		"a==b=c":       5,
+		`a=="foo`:      8,
+		"a=in=1":       6,
+		"a=in=(1,)":    9,
+		"a=isnull=yes": 10,
+	} {
+		// When
+		_, err := rsql.Parse(filter)
+
+		// Then
+		qe := collection.QueryError{}
+		require.ErrorAs(t, err, &qe, filter)
+		assert.Equal(t, position, qe.Position, filter)
+		assert.Contains(t, qe.Message, filter)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"status==open;priority=gt=2,assignee==me",
+		"(a==1,b!=2);c=in=(x,'y',\"z\")",
+		"a=isnull=true;b=like='%x_'",
+		`a=="\"",b=='\\'`,
+		"((a<1",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, filter string) {
+		expr, err := rsql.Parse(filter)
+		if err != nil {
+			assert.ErrorAs(t, err, &collection.QueryError{})
+			return
+		}
+		assert.NotNil(t, expr)
+	})
+}
@@ -0,0 +1,131 @@
+package collection
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// ValueType describes the type of the values of a field.
+type ValueType string
+
+// value types
+const (
+	TypeString  ValueType = "string"  // any text
+	TypeInt     ValueType = "int"     // a whole number, as int64
+	TypeDecimal ValueType = "decimal" // a decimal number, as float64
+	TypeBool    ValueType = "bool"    // true or false, as bool
+	TypeTime    ValueType = "time"    // an RFC 3339 timestamp or a time relative to now, as time.Time
+	TypeUUID    ValueType = "uuid"    // a UUID, as a lower case string
+	TypeEnum    ValueType = "enum"    // one of a set of strings
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// convert returns a filter value given as text converted to the type of the field.
+// Returns QueryError if the text is not a valid value of the field.
+func (f FieldCapability) convert(field string, text string) (any, error) {
+	var (
+		value any
+		err   error
+	)
+
+	switch f.Type {
+	case "", TypeString:
+		value = text
+	case TypeInt:
+		value, err = strconv.ParseInt(text, 10, 64)
+	case TypeDecimal:
+		value, err = strconv.ParseFloat(text, 64)
+	case TypeBool:
+		value, err = strconv.ParseBool(text)
+	case TypeTime:
+		value, err = parseTime(text)
+	case TypeUUID:
+		if !uuidPattern.MatchString(text) {
+			return nil, f.invalidValue(field, text)
+		}
+		value = strings.ToLower(text)
+	case TypeEnum:
+		if !slices.Contains(f.Values, text) {
+			return nil, f.invalidValue(field, text)
+		}
+		value = text
+	default:
+		return nil, fmt.Errorf("unknown value type: %s", f.Type)
+	}
+
+	if err != nil {
+		return nil, f.invalidValue(field, text)
+	}
+
+	return value, nil
+}
+
+// parseTime returns the time of an RFC 3339 timestamp, or of now or now offset by a duration,
+// e.g. now-24h or now+30m.
+func parseTime(text string) (time.Time, error) {
+	offset, ok := strings.CutPrefix(text, "now")
+	if !ok {
+		return time.Parse(time.RFC3339Nano, text)
+	}
+
+	now := time.Now().UTC()
+	if offset == "" {
+		return now, nil
+	}
+	if offset[0] != '-' && offset[0] != '+' {
+		return time.Time{}, fmt.Errorf("invalid relative time: %s", text)
+	}
+
+	d, err := time.ParseDuration(offset)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return now.Add(d), nil
+}
+
+// invalidValue returns the error for text that is not a valid value of the field.
+func (f FieldCapability) invalidValue(field string, text string) QueryError {
+	var reason string
+	switch f.Type {
+	case TypeTime:
+		reason = "is not a valid RFC 3339 time or relative time"
+	case TypeEnum:
+		reason = fmt.Sprintf("is not one of %s", strings.Join(f.Values, ", "))
+	default:
+		reason = fmt.Sprintf("is not a valid %s", f.Type)
+	}
+
+	return QueryError{Message: fmt.Sprintf("invalid filter value for %s: %s %s", field, text, reason)}
+}
+
+// convertFilter returns the filter with its text values converted to the type of the field.
+// Values that are not text, such as the true or false of a null check, are unchanged.
+func (f FieldCapability) convertFilter(filter FilterExpr) (FilterExpr, error) {
+	switch v := filter.Value.(type) {
+	case string:
+		value, err := f.convert(filter.Field, v)
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		filter.Value = value
+	case []string:
+		values := make([]any, len(v))
+		for i, text := range v {
+			value, err := f.convert(filter.Field, text)
+			if err != nil {
+				return FilterExpr{}, err
+			}
+			values[i] = value
+		}
+		filter.Value = values
+	}
+
+	return filter, nil
+}
@@ -8,3 +8,31 @@ type MediaError struct {
 func (me MediaError) Error() string {
 	return me.Message
 }
+
+// UnsupportedMediaTypeError is returned when a received resource is not in a supported format.
+type UnsupportedMediaTypeError struct {
+	Message string
+}
+
+func (ue UnsupportedMediaTypeError) Error() string {
+	return ue.Message
+}
+
+// PatchError is returned when a patch cannot be applied to a resource.
+type PatchError struct {
+	Message string
+}
+
+func (pe PatchError) Error() string {
+	return pe.Message
+}
+
+// PatchConflictError is returned when a patch conflicts with the state of a resource, such as when
+// a JSON Patch test operation fails.
+type PatchConflictError struct {
+	Message string
+}
+
+func (pe PatchConflictError) Error() string {
+	return pe.Message
+}
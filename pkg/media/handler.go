@@ -12,6 +12,12 @@ type Handler interface {
 	// If the expected resource cannot be parsed correctly a MediaError is returned.
 	ReadResource(r *http.Request, resource any) error
 
+	// Read a patch from a request.
+	// The supported patch formats are determined by the handler implementation.
+	// If the patch format is not supported an UnsupportedMediaTypeError is returned, and if the patch
+	// cannot be parsed correctly a MediaError is returned.
+	ReadPatch(r *http.Request) (Patch, error)
+
 	// Writes the given resource to the response writer with the given status code.
 	// The resource format is determined by the handler implementation.
 	WriteResponse(w http.ResponseWriter, statusCode int, resource any)
@@ -19,4 +25,26 @@ type Handler interface {
 	// Writes the given error to the response.
 	// Status code and error format is determined by the handler implementation.
 	WriteError(w http.ResponseWriter, err error)
+
+	// Returns the status code and error resource that WriteError would write for the given error,
+	// for errors written as part of another resource.
+	FormatError(err error) (int, any)
+}
+
+// SparseResource is a resource of which only the given fields are written to a response.
+// Fields name the top level properties of the resource or, if Items is set, the top level
+// properties of each item in the list of items named by Items.
+type SparseResource struct {
+	Resource any
+	Fields   []string
+	Items    string
+}
+
+// Patch is a set of changes to a resource.
+type Patch interface {
+
+	// Apply the changes to the resource, storing the changed resource in patched.
+	// If the changes conflict with the state of the resource a PatchConflictError is returned, and if
+	// they cannot be applied to the resource a PatchError is returned.
+	Apply(resource any, patched any) error
 }
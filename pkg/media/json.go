@@ -39,7 +39,16 @@ func (j JSONHandler) ReadResource(req *http.Request, resource any) error {
 // Encodes the resource into JSON and writes into response body.  Sets Content-Type to "application/json" and
 // status code on the response.
 // Panics if the given resource cannot be encoded to JSON.
+// A SparseResource is encoded with only its given fields.
 func (j JSONHandler) WriteResponse(resp http.ResponseWriter, status int, resource any) {
+	if sparse, ok := resource.(SparseResource); ok {
+		projected, err := projectJSON(sparse)
+		if err != nil {
+			log.Panicf("Could not encode resource to JSON: %v", err)
+		}
+		resource = projected
+	}
+
 	resp.Header().Set("Content-Type", "application/json")
 	resp.WriteHeader(status)
 	if resource != nil {
@@ -50,11 +59,60 @@ func (j JSONHandler) WriteResponse(resp http.ResponseWriter, status int, resourc
 	}
 }
 
+// projectJSON returns the JSON properties of a sparse resource, keeping only the given fields of
+// the resource or of each of its items.
+func projectJSON(sparse SparseResource) (any, error) {
+	jsonBytes, err := json.Marshal(sparse.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := map[string]json.RawMessage{}
+	if err := json.Unmarshal(jsonBytes, &resource); err != nil {
+		return nil, err
+	}
+	if sparse.Items == "" {
+		return keepFields(resource, sparse.Fields), nil
+	}
+
+	items := []map[string]json.RawMessage{}
+	if err := json.Unmarshal(resource[sparse.Items], &items); err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = keepFields(item, sparse.Fields)
+	}
+	itemBytes, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	resource[sparse.Items] = itemBytes
+
+	return resource, nil
+}
+
+// keepFields returns the given fields of an object.
+func keepFields(object map[string]json.RawMessage, fields []string) map[string]json.RawMessage {
+	kept := map[string]json.RawMessage{}
+	for _, f := range fields {
+		if v, ok := object[f]; ok {
+			kept[f] = v
+		}
+	}
+	return kept
+}
+
 // Encodes the error into JSON and writes into response body. Sets Content-type to "application/json".
 // The formatting of the JSON and the status code returned are retrieved from the handler's error map. See
 // ErrorMap.MapError.
 func (j JSONHandler) WriteError(resp http.ResponseWriter, err error) {
 	resp.Header().Set("Content-Type", "application/json")
-	statusCode, errorResource := j.ErrorMap.MapError(err)
+	statusCode, errorResource := j.FormatError(err)
 	j.WriteResponse(resp, statusCode, errorResource)
 }
+
+// Returns the status code and error resource for the error from the handler's error map. See
+// ErrorMap.MapError.
+func (j JSONHandler) FormatError(err error) (int, any) {
+	return j.ErrorMap.MapError(err)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +36,33 @@ func mockRequest(body any) *http.Request {
 	request, _ := http.NewRequest(http.MethodPost, "http://example.com", bytes.NewReader(json))
 	return request
 }
+
+func TestShouldWriteOnlyFieldsOfSparseResource(t *testing.T) {
+	// Given
+	handler := jsonMedia.JSONHandler{}
+	resp := httptest.NewRecorder()
+	resource := jsonMedia.SparseResource{Resource: validStruct{Foo: "mock foo", Bar: 1}, Fields: []string{"bar", "baz"}}
+
+	// When
+	handler.WriteResponse(resp, http.StatusOK, resource)
+
+	// Then
+	assert.JSONEq(t, `{"bar":1}`, resp.Body.String())
+}
+
+func TestShouldWriteOnlyFieldsOfSparseResourceItems(t *testing.T) {
+	// Given
+	handler := jsonMedia.JSONHandler{}
+	resp := httptest.NewRecorder()
+	resource := jsonMedia.SparseResource{
+		Resource: map[string]any{"items": []validStruct{{Foo: "a", Bar: 1}, {Foo: "b", Bar: 2}}, "size": 2},
+		Fields:   []string{"foo"},
+		Items:    "items",
+	}
+
+	// When
+	handler.WriteResponse(resp, http.StatusOK, resource)
+
+	// Then
+	assert.JSONEq(t, `{"items":[{"foo":"a"},{"foo":"b"}],"size":2}`, resp.Body.String())
+}
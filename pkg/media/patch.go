@@ -0,0 +1,96 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Content types of the patches read by JSONHandler.
+const (
+	MergePatchType = "application/merge-patch+json" // JSON Merge Patch, RFC 7396
+	JSONPatchType  = "application/json-patch+json"  // JSON Patch, RFC 6902
+)
+
+// mergePatch is a JSON Merge Patch.
+type mergePatch []byte
+
+// jsonPatch is a JSON Patch.
+type jsonPatch jsonpatch.Patch
+
+// Reads a JSON Merge Patch or JSON Patch from the request body, as given by the Content-Type.
+// Returns UnsupportedMediaTypeError if the Content-Type is neither, or MediaError if the request body
+// is not a valid patch.
+func (j JSONHandler) ReadPatch(req *http.Request) (Patch, error) {
+	contentType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if contentType != MergePatchType && contentType != JSONPatchType {
+		return nil, UnsupportedMediaTypeError{
+			Message: fmt.Sprintf("unsupported patch type: %q, expected %s or %s", contentType, MergePatchType, JSONPatchType),
+		}
+	}
+
+	jsonBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+	if !json.Valid(jsonBytes) {
+		return nil, MediaError{Message: "invalid json"}
+	}
+
+	if contentType == MergePatchType {
+		return mergePatch(jsonBytes), nil
+	}
+
+	patch, err := jsonpatch.DecodePatch(jsonBytes)
+	if err != nil {
+		return nil, MediaError{Message: fmt.Sprintf("invalid json patch: %v", err)}
+	}
+	return jsonPatch(patch), nil
+}
+
+// Apply merges the patch into the JSON of the resource.
+func (m mergePatch) Apply(resource any, patched any) error {
+	return applyJSON(resource, patched, func(doc []byte) ([]byte, error) {
+		return jsonpatch.MergePatch(doc, m)
+	})
+}
+
+// Apply applies the operations of the patch in order to the JSON of the resource. If any operation
+// fails none are applied. A failed test operation is a PatchConflictError.
+func (p jsonPatch) Apply(resource any, patched any) error {
+	return applyJSON(resource, patched, jsonpatch.Patch(p).Apply)
+}
+
+// applyJSON applies a patch function to the JSON of the resource and decodes the result into patched.
+func applyJSON(resource any, patched any, patch func([]byte) ([]byte, error)) error {
+	doc, err := json.Marshal(resource)
+	if err != nil {
+		return fmt.Errorf("failed to encode resource: %w", err)
+	}
+
+	doc, err = patch(doc)
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return PatchConflictError{Message: fmt.Sprintf("patch failed: %v", err)}
+	case err != nil:
+		return PatchError{Message: fmt.Sprintf("patch failed: %v", err)}
+	}
+
+	err = json.Unmarshal(doc, patched)
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *json.UnmarshalTypeError:
+		if e.Field == "" {
+			return PatchError{Message: fmt.Sprintf("patch failed: invalid type of patched resource: %s", e.Value)}
+		}
+		return PatchError{Message: fmt.Sprintf("patch failed: invalid type for field: %s", e.Field)}
+	default:
+		return fmt.Errorf("failed to decode patched resource: %w", err)
+	}
+}
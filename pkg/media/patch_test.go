@@ -0,0 +1,114 @@
+package media_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jsonMedia "github.com/grantjforrester/go-ticket/pkg/media"
+)
+
+func TestShouldApplyMergePatch(t *testing.T) {
+	// Given
+	handler := jsonMedia.JSONHandler{}
+	patch, err := handler.ReadPatch(mockPatchRequest(jsonMedia.MergePatchType, `{"foo": "patched foo"}`))
+	require.NoError(t, err)
+	patched := validStruct{}
+
+	// When
+	err = patch.Apply(validStruct{Foo: "mock foo", Bar: 1}, &patched)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, validStruct{Foo: "patched foo", Bar: 1}, patched)
+}
+
+func TestShouldApplyJSONPatch(t *testing.T) {
+	// Given
+	handler := jsonMedia.JSONHandler{}
+	patch, err := handler.ReadPatch(mockPatchRequest(jsonMedia.JSONPatchType+"; charset=utf-8",
+		`[{"op": "test", "path": "/bar", "value": 1}, {"op": "replace", "path": "/bar", "value": 2}]`))
+	require.NoError(t, err)
+	patched := validStruct{}
+
+	// When
+	err = patch.Apply(validStruct{Foo: "mock foo", Bar: 1}, &patched)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, validStruct{Foo: "mock foo", Bar: 2}, patched)
+}
+
+func TestShouldReturnConflictOnFailedTest(t *testing.T) {
+	// Given
+	handler := jsonMedia.JSONHandler{}
+	patch, err := handler.ReadPatch(mockPatchRequest(jsonMedia.JSONPatchType,
+		`[{"op": "test", "path": "/bar", "value": 2}, {"op": "replace", "path": "/foo", "value": "patched foo"}]`))
+	require.NoError(t, err)
+	patched := validStruct{}
+
+	// When
+	err = patch.Apply(validStruct{Foo: "mock foo", Bar: 1}, &patched)
+
+	// Then
+	assert.ErrorAs(t, err, &jsonMedia.PatchConflictError{})
+	assert.Equal(t, validStruct{}, patched)
+}
+
+func TestShouldReturnPatchErrorIfPatchCannotBeApplied(t *testing.T) {
+	tests := map[string]string{
+		jsonMedia.JSONPatchType:  `[{"op": "remove", "path": "/missing"}]`,
+		jsonMedia.MergePatchType: `{"bar": "not a number"}`,
+	}
+
+	for contentType, body := range tests {
+		// Given
+		handler := jsonMedia.JSONHandler{}
+		patch, err := handler.ReadPatch(mockPatchRequest(contentType, body))
+		require.NoError(t, err)
+
+		// When
+		err = patch.Apply(validStruct{Foo: "mock foo", Bar: 1}, &validStruct{})
+
+		// Then
+		assert.ErrorAs(t, err, &jsonMedia.PatchError{}, contentType)
+	}
+}
+
+func TestShouldReturnErrorOnInvalidPatch(t *testing.T) {
+	tests := map[string]string{
+		jsonMedia.JSONPatchType:  `[{"op": "frobnicate", "path": "/foo"}]`,
+		jsonMedia.MergePatchType: `{"foo": `,
+	}
+
+	for contentType, body := range tests {
+		// Given
+		handler := jsonMedia.JSONHandler{}
+
+		// When
+		_, err := handler.ReadPatch(mockPatchRequest(contentType, body))
+
+		// Then
+		assert.ErrorAs(t, err, &jsonMedia.MediaError{}, contentType)
+	}
+}
+
+func TestShouldReturnErrorOnUnsupportedPatchType(t *testing.T) {
+	// Given
+	handler := jsonMedia.JSONHandler{}
+
+	// When
+	_, err := handler.ReadPatch(mockPatchRequest("application/json", `{"foo": "patched foo"}`))
+
+	// Then
+	assert.ErrorAs(t, err, &jsonMedia.UnsupportedMediaTypeError{})
+}
+
+func mockPatchRequest(contentType string, body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPatch, "http://example.com", strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	return request
+}
@@ -0,0 +1,4 @@
+// Migrate provides a common pattern for versioning and upgrading the schema of a
+// Postgres database from a set of SQL migration files.
+
+package migrate
@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration is a versioned change to a database schema.
+type Migration struct {
+
+	// Version orders migrations. Migrations are applied in ascending order of version.
+	Version uint64
+
+	// Name describes the migration.
+	Name string
+
+	// Up is the SQL that applies the migration.
+	Up string
+
+	// Down is the SQL that reverts the migration.
+	Down string
+}
+
+// filenamePattern matches migration files named <version>.<name>.<up|down>.sql
+var filenamePattern = regexp.MustCompile(`^(\d+)\.(.+)\.(up|down)\.sql$`)
+
+// Load reads migrations from the files in the root of the file system. Every migration must have an
+// up file and may have a down file. Files not named as migrations are ignored.
+// Returns the migrations ordered by version, or error.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		parts := filenamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || parts == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+
+		sql, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration: %w", err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = m
+		}
+		if m.Name != parts[2] {
+			return nil, fmt.Errorf("duplicate migration version: %d", version)
+		}
+
+		if parts[3] == "up" {
+			m.Up = string(sql)
+		} else {
+			m.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("missing up migration: %d.%s", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
@@ -0,0 +1,61 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/migrate"
+)
+
+func TestShouldLoadMigrationsInVersionOrder(t *testing.T) {
+	// Given
+	fsys := fstest.MapFS{
+		"10.create_bar.up.sql":   {Data: []byte("CREATE TABLE bar ();")},
+		"2.create_foo.up.sql":    {Data: []byte("CREATE TABLE foo ();")},
+		"2.create_foo.down.sql":  {Data: []byte("DROP TABLE foo;")},
+		"README.md":              {Data: []byte("not a migration")},
+		"10.create_bar.down.sql": {Data: []byte("DROP TABLE bar;")},
+	}
+
+	// When
+	migrations, err := migrate.Load(fsys)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []migrate.Migration{
+		{Version: 2, Name: "create_foo", Up: "CREATE TABLE foo ();", Down: "DROP TABLE foo;"},
+		{Version: 10, Name: "create_bar", Up: "CREATE TABLE bar ();", Down: "DROP TABLE bar;"},
+	}, migrations)
+}
+
+func TestShouldReturnErrorOnMissingUpMigration(t *testing.T) {
+	// Given
+	fsys := fstest.MapFS{
+		"01.create_foo.down.sql": {Data: []byte("DROP TABLE foo;")},
+	}
+
+	// When
+	_, err := migrate.Load(fsys)
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "create_foo")
+}
+
+func TestShouldReturnErrorOnDuplicateVersion(t *testing.T) {
+	// Given
+	fsys := fstest.MapFS{
+		"01.create_foo.up.sql": {Data: []byte("CREATE TABLE foo ();")},
+		"01.create_bar.up.sql": {Data: []byte("CREATE TABLE bar ();")},
+	}
+
+	// When
+	_, err := migrate.Load(fsys)
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate")
+}
@@ -0,0 +1,249 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// lockID identifies the Postgres advisory lock held while migrating.
+const lockID int64 = 0x7469636b6574 // "ticket"
+
+// Migrator applies migrations to a Postgres database and records the applied versions in the
+// schema_migrations table. Changes are made while holding an advisory lock so that concurrent
+// migrators wait for each other.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// Status describes whether a migration has been applied.
+type Status struct {
+	Migration
+
+	// AppliedAt is the time the migration was applied, or nil if pending.
+	AppliedAt *time.Time
+}
+
+// NewMigrator creates a new Migrator for the migrations in the file system. See Load.
+func NewMigrator(db *sql.DB, fsys fs.FS) (Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return Migrator{}, err
+	}
+
+	return Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction.
+// Returns the applied migrations, or error.
+func (m Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		if err := createMigrationsTable(ctx, conn); err != nil {
+			return err
+		}
+
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+					migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d.%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the most recently applied migration in a transaction.
+// Returns the reverted migration, or nil if no migrations are applied, or error.
+func (m Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		if err := createMigrationsTable(ctx, conn); err != nil {
+			return err
+		}
+
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d.%s cannot be reverted", migration.Version, migration.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d.%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = &migration
+			return nil
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status returns every migration and whether it has been applied, ordered by version. The database
+// is not changed, so no migrations are applied if the schema_migrations table does not exist.
+func (m Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get connection: %w", err)
+	}
+	defer conn.Close()
+
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Migration: migration}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+
+	return statuses, nil
+}
+
+// Verify returns error if any migration is pending.
+func (m Migrator) Verify(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			return fmt.Errorf("migration %d.%s is pending", s.Version, s.Name)
+		}
+	}
+
+	return nil
+}
+
+// withLock runs f on a connection holding the migration advisory lock.
+func (m Migrator) withLock(ctx context.Context, f func(*sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("could not acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, unlockErr := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+		err = errors.Join(err, unlockErr)
+	}()
+
+	return f(conn)
+}
+
+// createMigrationsTable creates the schema_migrations table if necessary. It must only be called
+// while holding the migration lock.
+func createMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations
+		(
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("could not create schema_migrations table: %w", err)
+	}
+
+	return nil
+}
+
+// appliedVersions returns the applied versions and the time each was applied, or no versions if
+// the schema_migrations table does not exist.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[uint64]time.Time, error) {
+	var exists bool
+	err := conn.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("could not find schema_migrations table: %w", err)
+	}
+	if !exists {
+		return map[uint64]time.Time{}, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("could not read schema_migrations table: %w", err)
+	}
+	defer rows.Close()
+
+	versions := map[uint64]time.Time{}
+	for rows.Next() {
+		var (
+			version   uint64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("error reading row: %w", err)
+		}
+		versions[version] = appliedAt
+	}
+
+	return versions, rows.Err()
+}
+
+// inTx runs f in a transaction on the connection, committing if f succeeds.
+func inTx(ctx context.Context, conn *sql.Conn, f func(*sql.Tx) error) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not start tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -14,15 +14,18 @@ type Repository[T any] interface {
 	Create(Tx, T) (T, error)
 
 	// Finds an entity by its unique id using the given transaction.
-	// Returns the found entity, or error.
+	// Returns the found entity, or NotFoundError if there is no such entity, or error.
 	Read(Tx, string) (T, error)
 
 	// Updates the entity using the given transaction.
-	// Returns the updated entity, or error.
+	// Returns the updated entity, or NotFoundError if there is no such entity, or ConflictError if the
+	// entity has been modified since it was read, or error.
 	Update(Tx, T) (T, error)
 
-	// Deletes an entity with the unique id using the given transaction.
-	Delete(Tx, string) error
+	// Deletes an entity with the unique id and, unless it is "", the version using the given transaction.
+	// Returns NotFoundError if there is no such entity, or ConflictError if the entity has been modified
+	// since the version was read, or error.
+	Delete(tx Tx, id string, version string) error
 
 	// Finds entities based on the criteria in the query using the given transaction.
 	// Returns a page of matching entities, or error.
@@ -0,0 +1,4 @@
+// Repositorytest provides a conformance test suite that any implementation of
+// repository.Repository can run to prove it behaves as the interface describes.
+
+package repositorytest
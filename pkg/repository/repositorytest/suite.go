@@ -0,0 +1,460 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/collection"
+	"github.com/grantjforrester/go-ticket/pkg/collection/cql"
+	"github.com/grantjforrester/go-ticket/pkg/repository"
+)
+
+// Suite describes how to test a Repository implementation that stores entities of type T.
+type Suite[T any] struct {
+
+	// NewRepository returns an empty repository. It is called at the start of each test.
+	NewRepository func(t *testing.T) repository.Repository[T]
+
+	// NewEntity returns the i-th of a series of distinct entities that have not been created.
+	NewEntity func(i int) T
+
+	// Modify returns the entity with one or more properties changed.
+	Modify func(entity T) T
+
+	// ID returns the unique id of an entity.
+	ID func(entity T) string
+
+	// Version returns the version of an entity.
+	Version func(entity T) string
+
+	// Field names a property of the entities that can be used in query filters and sorts.
+	// The value of the property must increase with i for the entities returned by NewEntity.
+	Field string
+
+	// FieldValue returns the value of Field for NewEntity(i), as given in a query filter.
+	FieldValue func(i int) any
+}
+
+// Run runs every conformance test of the suite as a subtest of t.
+func (s Suite[T]) Run(t *testing.T) {
+	t.Run("CreateAndRead", s.testCreateAndRead)
+	t.Run("ReadMissing", s.testReadMissing)
+	t.Run("Update", s.testUpdate)
+	t.Run("UpdateMissing", s.testUpdateMissing)
+	t.Run("UpdateStaleVersion", s.testUpdateStaleVersion)
+	t.Run("Delete", s.testDelete)
+	t.Run("DeleteMissing", s.testDeleteMissing)
+	t.Run("DeleteVersion", s.testDeleteVersion)
+	t.Run("DeleteStaleVersion", s.testDeleteStaleVersion)
+	t.Run("RollbackCreate", s.testRollbackCreate)
+	t.Run("RollbackUpdate", s.testRollbackUpdate)
+	t.Run("QueryAll", s.testQueryAll)
+	t.Run("QueryFilter", s.testQueryFilter)
+	t.Run("QueryBooleanFilter", s.testQueryBooleanFilter)
+	t.Run("QuerySetFilter", s.testQuerySetFilter)
+	t.Run("QuerySort", s.testQuerySort)
+	t.Run("QueryPage", s.testQueryPage)
+	t.Run("QueryPageBeyondResults", s.testQueryPageBeyondResults)
+	t.Run("QueryCount", s.testQueryCount)
+	t.Run("QueryAfterCursor", s.testQueryAfterCursor)
+}
+
+func (s Suite[T]) testCreateAndRead(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+
+	// When
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+
+	// Then
+	assert.NotEmpty(t, s.ID(created))
+	assert.NotEmpty(t, s.Version(created))
+	assert.Equal(t, created, s.mustRead(t, repo, s.ID(created)))
+}
+
+func (s Suite[T]) testReadMissing(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+	s.mustDelete(t, repo, s.ID(created))
+
+	// When
+	_, err := s.read(t, repo, s.ID(created))
+
+	// Then
+	assert.ErrorAs(t, err, &repository.NotFoundError{})
+}
+
+func (s Suite[T]) testUpdate(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+
+	// When
+	tx := s.mustStartTx(t, repo, false)
+	updated, err := repo.Update(tx, s.Modify(created))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	// Then
+	assert.Equal(t, s.ID(created), s.ID(updated))
+	assert.NotEqual(t, s.Version(created), s.Version(updated))
+	assert.NotEqual(t, created, updated)
+	assert.Equal(t, updated, s.mustRead(t, repo, s.ID(created)))
+}
+
+func (s Suite[T]) testUpdateMissing(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+	s.mustDelete(t, repo, s.ID(created))
+
+	// When
+	tx := s.mustStartTx(t, repo, false)
+	defer tx.Rollback()
+	_, err := repo.Update(tx, s.Modify(created))
+
+	// Then
+	assert.ErrorAs(t, err, &repository.NotFoundError{})
+}
+
+func (s Suite[T]) testUpdateStaleVersion(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+	tx := s.mustStartTx(t, repo, false)
+	_, err := repo.Update(tx, s.Modify(created))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	// When
+	tx = s.mustStartTx(t, repo, false)
+	defer tx.Rollback()
+	_, err = repo.Update(tx, s.Modify(created))
+
+	// Then
+	assert.ErrorAs(t, err, &repository.ConflictError{})
+}
+
+func (s Suite[T]) testDelete(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1))
+
+	// When
+	s.mustDelete(t, repo, s.ID(created[0]))
+
+	// Then
+	_, err := s.read(t, repo, s.ID(created[0]))
+	assert.ErrorAs(t, err, &repository.NotFoundError{})
+	assert.Equal(t, created[1], s.mustRead(t, repo, s.ID(created[1])))
+}
+
+func (s Suite[T]) testDeleteMissing(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+	s.mustDelete(t, repo, s.ID(created))
+
+	// When
+	tx := s.mustStartTx(t, repo, false)
+	defer tx.Rollback()
+	err := repo.Delete(tx, s.ID(created), "")
+
+	// Then
+	assert.ErrorAs(t, err, &repository.NotFoundError{})
+}
+
+func (s Suite[T]) testDeleteVersion(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+
+	// When
+	tx := s.mustStartTx(t, repo, false)
+	require.NoError(t, repo.Delete(tx, s.ID(created), s.Version(created)))
+	require.NoError(t, tx.Commit())
+
+	// Then
+	_, err := s.read(t, repo, s.ID(created))
+	assert.ErrorAs(t, err, &repository.NotFoundError{})
+}
+
+func (s Suite[T]) testDeleteStaleVersion(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+	tx := s.mustStartTx(t, repo, false)
+	updated, err := repo.Update(tx, s.Modify(created))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	// When
+	tx = s.mustStartTx(t, repo, false)
+	err = repo.Delete(tx, s.ID(created), s.Version(created))
+	require.NoError(t, tx.Rollback())
+
+	// Then
+	assert.ErrorAs(t, err, &repository.ConflictError{})
+	assert.Equal(t, updated, s.mustRead(t, repo, s.ID(created)))
+}
+
+func (s Suite[T]) testRollbackCreate(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	tx := s.mustStartTx(t, repo, false)
+	created, err := repo.Create(tx, s.NewEntity(0))
+	require.NoError(t, err)
+
+	// When
+	require.NoError(t, tx.Rollback())
+
+	// Then
+	_, err = s.read(t, repo, s.ID(created))
+	assert.ErrorAs(t, err, &repository.NotFoundError{})
+}
+
+func (s Suite[T]) testRollbackUpdate(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0))[0]
+	tx := s.mustStartTx(t, repo, false)
+	_, err := repo.Update(tx, s.Modify(created))
+	require.NoError(t, err)
+
+	// When
+	require.NoError(t, tx.Rollback())
+
+	// Then
+	assert.Equal(t, created, s.mustRead(t, repo, s.ID(created)))
+}
+
+func (s Suite[T]) testQueryAll(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1), s.NewEntity(2))
+
+	// When
+	page := s.mustQuery(t, repo, collection.QuerySpec{Page: 1, Size: 10})
+
+	// Then
+	assert.Equal(t, uint64(1), page.Page)
+	assert.Equal(t, uint64(3), page.Size)
+	assert.ElementsMatch(t, created, page.Results)
+}
+
+func (s Suite[T]) testQueryFilter(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1), s.NewEntity(2))
+
+	// When
+	eq := s.mustQuery(t, repo, collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: s.Field, Operator: cql.OpEq, Value: s.FieldValue(1)}},
+	})
+	gt := s.mustQuery(t, repo, collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: s.Field, Operator: cql.OpGt, Value: s.FieldValue(0)}},
+	})
+
+	// Then
+	assert.Equal(t, []T{created[1]}, eq.Results)
+	assert.ElementsMatch(t, []T{created[1], created[2]}, gt.Results)
+}
+
+func (s Suite[T]) testQueryBooleanFilter(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1), s.NewEntity(2), s.NewEntity(3))
+
+	// When
+	page := s.mustQuery(t, repo, collection.QuerySpec{
+		Filters: []collection.Expr{collection.OrExpr{
+			collection.FilterExpr{Field: s.Field, Operator: cql.OpEq, Value: s.FieldValue(0)},
+			collection.AndExpr{
+				collection.FilterExpr{Field: s.Field, Operator: cql.OpGt, Value: s.FieldValue(1)},
+				collection.NotExpr{Expr: collection.FilterExpr{Field: s.Field, Operator: cql.OpEq, Value: s.FieldValue(3)}},
+			},
+		}},
+	})
+
+	// Then
+	assert.ElementsMatch(t, []T{created[0], created[2]}, page.Results)
+}
+
+func (s Suite[T]) testQuerySetFilter(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1), s.NewEntity(2))
+	list := []string{fmt.Sprint(s.FieldValue(0)), fmt.Sprint(s.FieldValue(2))}
+
+	// When
+	in := s.mustQuery(t, repo, collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: s.Field, Operator: cql.OpIn, Value: list}},
+	})
+	out := s.mustQuery(t, repo, collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: s.Field, Operator: cql.OpOut, Value: list}},
+	})
+
+	// Then
+	assert.ElementsMatch(t, []T{created[0], created[2]}, in.Results)
+	assert.Equal(t, []T{created[1]}, out.Results)
+}
+
+func (s Suite[T]) testQuerySort(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(1), s.NewEntity(2), s.NewEntity(0))
+
+	// When
+	asc := s.mustQuery(t, repo, collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: s.Field, Direction: cql.SortAsc}},
+	})
+	desc := s.mustQuery(t, repo, collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: s.Field, Direction: cql.SortDesc}},
+	})
+
+	// Then
+	assert.Equal(t, []T{created[2], created[0], created[1]}, asc.Results)
+	assert.Equal(t, []T{created[1], created[0], created[2]}, desc.Results)
+}
+
+func (s Suite[T]) testQueryPage(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1), s.NewEntity(2), s.NewEntity(3), s.NewEntity(4))
+
+	// When
+	page := s.mustQuery(t, repo, collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: s.Field, Direction: cql.SortAsc}},
+		Page:  2,
+		Size:  2,
+	})
+	last := s.mustQuery(t, repo, collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: s.Field, Direction: cql.SortAsc}},
+		Page:  3,
+		Size:  2,
+	})
+
+	// Then
+	assert.Equal(t, uint64(2), page.Page)
+	assert.Equal(t, uint64(2), page.Size)
+	assert.Equal(t, []T{created[2], created[3]}, page.Results)
+	assert.Equal(t, uint64(3), last.Page)
+	assert.Equal(t, uint64(1), last.Size)
+	assert.Equal(t, []T{created[4]}, last.Results)
+}
+
+func (s Suite[T]) testQueryPageBeyondResults(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	s.mustCreate(t, repo, s.NewEntity(0))
+
+	// When
+	page := s.mustQuery(t, repo, collection.QuerySpec{Page: 2, Size: 10})
+
+	// Then
+	assert.Equal(t, uint64(0), page.Page)
+	assert.Equal(t, uint64(0), page.Size)
+	assert.Empty(t, page.Results)
+}
+
+func (s Suite[T]) testQueryCount(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	s.mustCreate(t, repo, s.NewEntity(0), s.NewEntity(1), s.NewEntity(2), s.NewEntity(3), s.NewEntity(4))
+
+	// When
+	counted := s.mustQuery(t, repo, collection.QuerySpec{
+		Filters: []collection.Expr{collection.FilterExpr{Field: s.Field, Operator: cql.OpGt, Value: s.FieldValue(0)}},
+		Page:    1,
+		Size:    3,
+		Count:   true,
+	})
+	uncounted := s.mustQuery(t, repo, collection.QuerySpec{Page: 1, Size: 3})
+
+	// Then
+	assert.Equal(t, uint64(3), counted.Size)
+	require.NotNil(t, counted.Total)
+	assert.Equal(t, uint64(4), *counted.Total)
+	require.NotNil(t, counted.Pages)
+	assert.Equal(t, uint64(2), *counted.Pages)
+	assert.Nil(t, uncounted.Total)
+	assert.Nil(t, uncounted.Pages)
+}
+
+func (s Suite[T]) testQueryAfterCursor(t *testing.T) {
+	// Given
+	repo := s.NewRepository(t)
+	created := s.mustCreate(t, repo, s.NewEntity(3), s.NewEntity(1), s.NewEntity(4), s.NewEntity(0), s.NewEntity(2))
+	query := collection.QuerySpec{
+		Sorts: []collection.SortExpr{{Field: s.Field, Direction: cql.SortDesc}},
+		Size:  2,
+	}
+
+	// When
+	results := []T{}
+	pages := 0
+	for {
+		page := s.mustQuery(t, repo, query)
+		results = append(results, page.Results...)
+		pages++
+		if page.Next == nil {
+			break
+		}
+		require.Less(t, pages, 5, "cursor does not advance")
+		query.After = page.Next
+	}
+
+	// Then
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []T{created[2], created[0], created[4], created[1], created[3]}, results)
+}
+
+func (s Suite[T]) mustStartTx(t *testing.T, repo repository.Repository[T], readOnly bool) repository.Tx {
+	tx, err := repo.StartTx(context.Background(), readOnly)
+	require.NoError(t, err)
+	return tx
+}
+
+// mustCreate creates the entities in a single committed transaction.
+func (s Suite[T]) mustCreate(t *testing.T, repo repository.Repository[T], entities ...T) []T {
+	tx := s.mustStartTx(t, repo, false)
+	created := make([]T, len(entities))
+	for i, e := range entities {
+		c, err := repo.Create(tx, e)
+		require.NoError(t, err)
+		created[i] = c
+	}
+	require.NoError(t, tx.Commit())
+	return created
+}
+
+func (s Suite[T]) read(t *testing.T, repo repository.Repository[T], id string) (T, error) {
+	tx := s.mustStartTx(t, repo, true)
+	defer tx.Rollback()
+	return repo.Read(tx, id)
+}
+
+func (s Suite[T]) mustRead(t *testing.T, repo repository.Repository[T], id string) T {
+	entity, err := s.read(t, repo, id)
+	require.NoError(t, err)
+	return entity
+}
+
+func (s Suite[T]) mustDelete(t *testing.T, repo repository.Repository[T], id string) {
+	tx := s.mustStartTx(t, repo, false)
+	require.NoError(t, repo.Delete(tx, id, ""))
+	require.NoError(t, tx.Commit())
+}
+
+func (s Suite[T]) mustQuery(t *testing.T, repo repository.Repository[T], query collection.QuerySpec) collection.Page[T] {
+	tx := s.mustStartTx(t, repo, true)
+	defer tx.Rollback()
+	page, err := repo.Query(tx, query)
+	require.NoError(t, err)
+	return page
+}
@@ -0,0 +1,71 @@
+package ticket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Comment represents a contribution to the discussion of a ticket.
+type Comment struct {
+
+	// TicketID identifies the ticket being discussed.
+	TicketID string `json:"ticket_id"`
+
+	// Author identifies who wrote the comment.
+	Author string `json:"author"`
+
+	// Body is the text of the comment.
+	Body string `json:"body"`
+}
+
+// Validate checks the mandatory comment properties are valid. Returns error if validation fails.
+func (c Comment) Validate() error {
+	errs := []string{}
+
+	if c.TicketID == "" {
+		errs = append(errs, "missing field: ticket_id")
+	}
+
+	if c.Author == "" {
+		errs = append(errs, "missing field: author")
+	}
+
+	if c.Body == "" {
+		errs = append(errs, "missing field: body")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, ","))
+	}
+
+	return nil
+}
+
+// CommentWithMetadata merges the types Comment and Metadata.
+type CommentWithMetadata struct {
+
+	// Metadata identifies the comment.
+	Metadata
+
+	// Comment holds the comment details.
+	Comment
+}
+
+// Validate checks the comment and metadata properties are valid. Returns error if validation fails.
+func (c CommentWithMetadata) Validate() error {
+	errs := []string{}
+
+	if err := c.Metadata.Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := c.Comment.Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, ","))
+	}
+
+	return nil
+}
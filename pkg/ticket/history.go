@@ -0,0 +1,76 @@
+package ticket
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Action describes the kind of change made to a ticket.
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionRestore Action = "restore"
+	ActionPurge   Action = "purge"
+)
+
+// Change records a single ticket property changing value.
+type Change struct {
+
+	// Field is the name of the changed property.
+	Field string `json:"field"`
+
+	// Before is the value of the property before the change.
+	Before any `json:"before"`
+
+	// After is the value of the property after the change.
+	After any `json:"after"`
+}
+
+// HistoryEntry records a change made to a ticket. Entries are never modified once recorded.
+type HistoryEntry struct {
+
+	// ID is a unique identifier for the entry.
+	ID string `json:"id"`
+
+	// TicketID identifies the changed ticket.
+	TicketID string `json:"ticket_id"`
+
+	// Action describes the kind of change.
+	Action Action `json:"action"`
+
+	// ChangedBy identifies the principal that made the change.
+	ChangedBy string `json:"changed_by"`
+
+	// ChangedAt is the time the change was made.
+	ChangedAt time.Time `json:"changed_at"`
+
+	// Changes lists the ticket properties that changed.
+	Changes []Change `json:"changes"`
+}
+
+// Diff compares two tickets. Returns a Change for each property with a different value.
+func Diff(before Ticket, after Ticket) []Change {
+	changes := []Change{}
+	bv, av := reflect.ValueOf(before), reflect.ValueOf(after)
+
+	for i := 0; i < bv.NumField(); i++ {
+		b, a := bv.Field(i).Interface(), av.Field(i).Interface()
+		if !reflect.DeepEqual(b, a) {
+			changes = append(changes, Change{Field: fieldName(bv.Type().Field(i)), Before: b, After: a})
+		}
+	}
+
+	return changes
+}
+
+// fieldName returns the JSON name of a struct field.
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
@@ -0,0 +1,35 @@
+package ticket_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grantjforrester/go-ticket/pkg/ticket"
+)
+
+func TestShouldReturnNoChangesForEqualTickets(t *testing.T) {
+	// Given
+	before := ticket.Ticket{Summary: "foo", Status: "open"}
+
+	// When
+	changes := ticket.Diff(before, before)
+
+	// Then
+	assert.Empty(t, changes)
+}
+
+func TestShouldReturnChangedFields(t *testing.T) {
+	// Given
+	before := ticket.Ticket{Summary: "foo", Description: "bar", Status: "open"}
+	after := ticket.Ticket{Summary: "foo", Description: "baz", Status: "closed"}
+
+	// When
+	changes := ticket.Diff(before, after)
+
+	// Then
+	assert.Equal(t, []ticket.Change{
+		{Field: "description", Before: "bar", After: "baz"},
+		{Field: "status", Before: "open", After: "closed"},
+	}, changes)
+}
@@ -3,6 +3,7 @@ package ticket
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Metadata holds information common to all domain entities.
@@ -12,6 +13,18 @@ type Metadata struct {
 
 	// Version is an identifier that changes as the domain entity's properties change.
 	Version string `json:"version"`
+
+	// CreatedAt is the time the domain entity was created. Set by the repository.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is the time the domain entity was last changed. Set by the repository.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// CreatedBy identifies the principal that created the domain entity.
+	CreatedBy string `json:"created_by,omitempty"`
+
+	// UpdatedBy identifies the principal that last changed the domain entity.
+	UpdatedBy string `json:"updated_by,omitempty"`
 }
 
 // Validate checks metadata properties are valid. Returns error if validation fails.
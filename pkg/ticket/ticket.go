@@ -3,6 +3,9 @@ package ticket
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
 )
 
 // Ticket represents a reminder of work to be done in a typical ITSM.
@@ -14,10 +17,31 @@ type Ticket struct {
 	// Description is a full and detailed description of the work to be done.
 	Description string `json:"description"`
 
-	// Status describes whether the work has been completed.
+	// Status describes progress of the work as a state of the ticket workflow.
 	Status string `json:"status"`
+
+	// Priority describes how soon the work should be done, as one of Priorities.
+	Priority string `json:"priority"`
+
+	// Severity describes the impact of the problem the work fixes, as one of Severities. Optional.
+	Severity string `json:"severity,omitempty"`
+
+	// Assignee identifies the principal that should do the work. Optional.
+	Assignee string `json:"assignee,omitempty"`
+
+	// Reporter identifies the principal that reported the work to be done.
+	Reporter string `json:"reporter,omitempty"`
 }
 
+// Priorities lists the valid ticket priorities, from lowest to highest.
+var Priorities = []string{"low", "medium", "high", "critical"}
+
+// DefaultPriority is the priority of a ticket created without one.
+const DefaultPriority = "medium"
+
+// Severities lists the valid ticket severities, from least to most severe.
+var Severities = []string{"minor", "major", "critical"}
+
 // Validate checks the mandatory ticket properties are valid. Returns error if validation fails.
 func (t Ticket) Validate() error {
 	errs := []string{}
@@ -30,6 +54,14 @@ func (t Ticket) Validate() error {
 		errs = append(errs, "missing field: status")
 	}
 
+	if t.Priority != "" && !slices.Contains(Priorities, t.Priority) {
+		errs = append(errs, fmt.Sprintf("invalid field: priority must be one of %s", strings.Join(Priorities, ", ")))
+	}
+
+	if t.Severity != "" && !slices.Contains(Severities, t.Severity) {
+		errs = append(errs, fmt.Sprintf("invalid field: severity must be one of %s", strings.Join(Severities, ", ")))
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf(strings.Join(errs, ","))
 	}
@@ -45,6 +77,12 @@ type TicketWithMetadata struct {
 
 	// Ticket holds the ticket details.
 	Ticket
+
+	// DeletedAt is the time the ticket was moved to the trash. nil if the ticket is not in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// DeletedBy identifies the principal that moved the ticket to the trash.
+	DeletedBy string `json:"deleted_by,omitempty"`
 }
 
 // Validate checks the ticket and metadata properties are valid. Returns error if validation fails.
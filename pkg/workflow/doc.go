@@ -0,0 +1,4 @@
+// Workflow provides a common pattern for constraining the states of a resource and
+// the transitions allowed between them.
+
+package workflow
@@ -0,0 +1,11 @@
+package workflow
+
+// TransitionError is returned when a resource is placed in a state that is not
+// permitted by the workflow.
+type TransitionError struct {
+	Message string
+}
+
+func (te TransitionError) Error() string {
+	return te.Message
+}
@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Workflow describes the states a resource may be in and the transitions allowed between them.
+type Workflow struct {
+
+	// States lists every valid state.
+	States []string `json:"states" yaml:"states"`
+
+	// Initial is the state every new resource must start in.
+	Initial string `json:"initial" yaml:"initial"`
+
+	// Terminal lists the states from which no further transitions are allowed.
+	Terminal []string `json:"terminal" yaml:"terminal"`
+
+	// Transitions maps a state to the states it may move to.
+	Transitions map[string][]string `json:"transitions" yaml:"transitions"`
+}
+
+// Default is the workflow used when no other workflow is configured.
+var Default = Workflow{
+	States:   []string{"open", "in_progress", "resolved", "closed"},
+	Initial:  "open",
+	Terminal: []string{"closed"},
+	Transitions: map[string][]string{
+		"open":        {"in_progress", "closed"},
+		"in_progress": {"open", "resolved", "closed"},
+		"resolved":    {"in_progress", "closed"},
+	},
+}
+
+// Load reads a workflow from a YAML or JSON file. Returns the workflow, or error if the
+// file cannot be read or does not describe a valid workflow.
+func Load(filename string) (Workflow, error) {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return Workflow{}, fmt.Errorf("failed to read workflow: %w", err)
+	}
+
+	w := Workflow{}
+	if err := yaml.Unmarshal(bytes, &w); err != nil {
+		return Workflow{}, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+
+	if err := w.Validate(); err != nil {
+		return Workflow{}, fmt.Errorf("invalid workflow %s: %w", filename, err)
+	}
+
+	return w, nil
+}
+
+// Validate checks the workflow is internally consistent. Returns error if validation fails.
+func (w Workflow) Validate() error {
+	errs := []string{}
+
+	if len(w.States) == 0 {
+		errs = append(errs, "missing field: states")
+	}
+
+	if !w.IsState(w.Initial) {
+		errs = append(errs, fmt.Sprintf("initial state is not a state: %s", w.Initial))
+	}
+
+	for _, s := range w.Terminal {
+		if !w.IsState(s) {
+			errs = append(errs, fmt.Sprintf("terminal state is not a state: %s", s))
+		}
+	}
+
+	for from, tos := range w.Transitions {
+		if !w.IsState(from) {
+			errs = append(errs, fmt.Sprintf("transition from unknown state: %s", from))
+		}
+		if w.IsTerminal(from) && len(tos) > 0 {
+			errs = append(errs, fmt.Sprintf("transition from terminal state: %s", from))
+		}
+		for _, to := range tos {
+			if !w.IsState(to) {
+				errs = append(errs, fmt.Sprintf("transition to unknown state: %s", to))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(strings.Join(errs, ","))
+	}
+
+	return nil
+}
+
+// IsState returns true if the state is one of the workflow's states.
+func (w Workflow) IsState(state string) bool {
+	return slices.Contains(w.States, state)
+}
+
+// IsTerminal returns true if no transitions are allowed from the state.
+func (w Workflow) IsTerminal(state string) bool {
+	return slices.Contains(w.Terminal, state)
+}
+
+// CheckInitial checks a new resource may start in the state. Returns TransitionError if not.
+func (w Workflow) CheckInitial(state string) error {
+	if !w.IsState(state) {
+		return TransitionError{Message: fmt.Sprintf("unknown state: %s", state)}
+	}
+
+	if state != w.Initial {
+		return TransitionError{Message: fmt.Sprintf("invalid initial state: %s", state)}
+	}
+
+	return nil
+}
+
+// CheckTransition checks a resource may move from one state to another. Remaining in the same
+// state is always allowed. A resource in a state that is not a workflow state, such as one created
+// before the workflow was configured, may remain in it or move to any workflow state. Returns
+// TransitionError if not.
+func (w Workflow) CheckTransition(from string, to string) error {
+	if from == to {
+		return nil
+	}
+
+	if !w.IsState(to) {
+		return TransitionError{Message: fmt.Sprintf("unknown state: %s", to)}
+	}
+
+	if !w.IsState(from) {
+		return nil
+	}
+
+	if w.IsTerminal(from) {
+		return TransitionError{Message: fmt.Sprintf("no transitions allowed from terminal state: %s", from)}
+	}
+
+	if !slices.Contains(w.Transitions[from], to) {
+		return TransitionError{Message: fmt.Sprintf("transition not allowed: %s to %s", from, to)}
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package workflow_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grantjforrester/go-ticket/pkg/workflow"
+)
+
+func TestDefaultWorkflowIsValid(t *testing.T) {
+	// When
+	err := workflow.Default.Validate()
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAllowInitialState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckInitial("open")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldRejectNonInitialState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckInitial("closed")
+
+	// Then
+	assert.ErrorAs(t, err, &workflow.TransitionError{})
+	assert.Contains(t, err.Error(), "closed")
+}
+
+func TestShouldRejectUnknownState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("open", "Done")
+
+	// Then
+	assert.ErrorAs(t, err, &workflow.TransitionError{})
+	assert.Contains(t, err.Error(), "Done")
+}
+
+func TestShouldAllowTransition(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("open", "in_progress")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAllowSameState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("closed", "closed")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAllowLegacyStateToRemain(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("Done", "Done")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldAllowLegacyStateToMoveToAnyState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("Done", "closed")
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestShouldRejectLegacyStateMovingToUnknownState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("Done", "done")
+
+	// Then
+	assert.ErrorAs(t, err, &workflow.TransitionError{})
+	assert.Contains(t, err.Error(), "unknown state: done")
+}
+
+func TestShouldRejectTransitionNotAllowed(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("open", "resolved")
+
+	// Then
+	assert.ErrorAs(t, err, &workflow.TransitionError{})
+	assert.Contains(t, err.Error(), "open")
+	assert.Contains(t, err.Error(), "resolved")
+}
+
+func TestShouldRejectTransitionFromTerminalState(t *testing.T) {
+	// When
+	err := workflow.Default.CheckTransition("closed", "open")
+
+	// Then
+	assert.ErrorAs(t, err, &workflow.TransitionError{})
+	assert.Contains(t, err.Error(), "terminal")
+}
+
+func TestShouldReturnErrorOnInconsistentWorkflow(t *testing.T) {
+	// Given
+	w := workflow.Workflow{
+		States:      []string{"a", "b"},
+		Initial:     "c",
+		Terminal:    []string{"b"},
+		Transitions: map[string][]string{"a": {"d"}, "b": {"a"}},
+	}
+
+	// When
+	err := w.Validate()
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "initial state is not a state: c")
+	assert.Contains(t, err.Error(), "transition to unknown state: d")
+	assert.Contains(t, err.Error(), "transition from terminal state: b")
+}
+
+func TestShouldLoadWorkflowFromFile(t *testing.T) {
+	// Given
+	filename := filepath.Join(t.TempDir(), "workflow.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+states: [todo, done]
+initial: todo
+terminal: [done]
+transitions:
+  todo: [done]
+`), 0o600))
+
+	// When
+	w, err := workflow.Load(filename)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "todo", w.Initial)
+	assert.NoError(t, w.CheckTransition("todo", "done"))
+}
+
+func TestShouldLoadWorkflowFromJSONFile(t *testing.T) {
+	// Given
+	filename := filepath.Join(t.TempDir(), "workflow.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{
+		"states": ["todo", "done"],
+		"initial": "todo",
+		"terminal": ["done"],
+		"transitions": {"todo": ["done"]}
+	}`), 0o600))
+
+	// When
+	w, err := workflow.Load(filename)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"todo", "done"}, w.States)
+}
+
+func TestShouldReturnErrorLoadingInvalidWorkflow(t *testing.T) {
+	// Given
+	filename := filepath.Join(t.TempDir(), "workflow.yml")
+	require.NoError(t, os.WriteFile(filename, []byte("states: [todo]\ninitial: done\n"), 0o600))
+
+	// When
+	_, err := workflow.Load(filename)
+
+	// Then
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "initial")
+}